@@ -0,0 +1,129 @@
+package tracer
+
+import (
+	"context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rfyiamcool/go-tracer/internal/jaegerutil"
+)
+
+var (
+	_ opentracing.TextMapReader = MapCarrier{}
+	_ opentracing.TextMapReader = BytesMapCarrier{}
+	_ opentracing.TextMapReader = &HeadersCarrier{}
+	_ opentracing.TextMapReader = TableCarrier{}
+)
+
+// Inject inject the span context of ctx into carrier by the tracer, x-trace-id and w3c traceparent.
+//
+//	headers := tracer.BytesMapCarrier{}
+//	tracer.Inject(ctx, headers)
+func Inject(ctx context.Context, carrier opentracing.TextMapWriter) error {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return opentracing.ErrSpanContextNotFound
+	}
+	return jaegerutil.Inject(span, carrier)
+}
+
+// Extract extract the span context from carrier by the tracer, x-trace-id or w3c traceparent.
+//
+//	spctx, err := tracer.Extract(tracer.TableCarrier(delivery.Headers))
+//	span := tracer.StartSpan("consume", opentracing.FollowsFrom(spctx))
+func Extract(carrier opentracing.TextMapReader) (opentracing.SpanContext, error) {
+	return jaegerutil.Extract(opentracing.GlobalTracer(), carrier)
+}
+
+// MapCarrier map[string]string Reader and Writer
+type MapCarrier map[string]string
+
+// ForeachKey implements ForeachKey of opentracing.TextMapReader
+func (c MapCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, v := range c {
+		if err := handler(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set implements Set() of opentracing.TextMapWriter
+func (c MapCarrier) Set(key, val string) {
+	c[key] = val
+}
+
+// BytesMapCarrier map[string][]byte Reader and Writer
+type BytesMapCarrier map[string][]byte
+
+// ForeachKey implements ForeachKey of opentracing.TextMapReader
+func (c BytesMapCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, v := range c {
+		if err := handler(k, string(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set implements Set() of opentracing.TextMapWriter
+func (c BytesMapCarrier) Set(key, val string) {
+	c[key] = []byte(val)
+}
+
+// Header the key/value header of message, like sarama.RecordHeader, convert by Header(h).
+type Header struct {
+	Key   []byte
+	Value []byte
+}
+
+// HeadersCarrier []Header Reader and Writer, the header of same key is replaced by Set.
+type HeadersCarrier []Header
+
+// ForeachKey implements ForeachKey of opentracing.TextMapReader
+func (c *HeadersCarrier) ForeachKey(handler func(key, val string) error) error {
+	for _, header := range *c {
+		if err := handler(string(header.Key), string(header.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set implements Set() of opentracing.TextMapWriter
+func (c *HeadersCarrier) Set(key, val string) {
+	for i, header := range *c {
+		if string(header.Key) == key {
+			(*c)[i].Value = []byte(val)
+			return
+		}
+	}
+	*c = append(*c, Header{Key: []byte(key), Value: []byte(val)})
+}
+
+// TableCarrier AMQP-style table Reader and Writer, like amqp.Table, convert by TableCarrier(table).
+// the value is set as string, the value of string and []byte can be read.
+type TableCarrier map[string]interface{}
+
+// ForeachKey implements ForeachKey of opentracing.TextMapReader
+func (c TableCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, v := range c {
+		var val string
+		switch v := v.(type) {
+		case string:
+			val = v
+		case []byte:
+			val = string(v)
+		default:
+			continue
+		}
+		if err := handler(k, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set implements Set() of opentracing.TextMapWriter
+func (c TableCarrier) Set(key, val string) {
+	c[key] = val
+}
@@ -0,0 +1,41 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestCarrier(t *testing.T) {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+	SeteTracer(jtracer)
+
+	span, ctx := StartSpanFromContext(context.Background(), "produce")
+	defer span.Finish()
+
+	carriers := []interface {
+		opentracing.TextMapReader
+		opentracing.TextMapWriter
+	}{MapCarrier{}, BytesMapCarrier{}, &HeadersCarrier{}, TableCarrier{}}
+
+	for _, carrier := range carriers {
+		assert.Nil(t, Inject(ctx, carrier))
+
+		spctx, err := Extract(carrier)
+		assert.Nil(t, err)
+		assert.Equal(t, GetTraceID(span), spctx.(jaeger.SpanContext).TraceID().String())
+	}
+
+	// w3c traceparent only, like the message produced by opentelemetry.
+	spctx, err := Extract(TableCarrier{"traceparent": []byte("00-0000000000000001000000000000000f-0000000000000003-01")})
+	assert.Nil(t, err)
+	assert.Equal(t, "0000000000000001000000000000000f", spctx.(jaeger.SpanContext).TraceID().String())
+
+	_, err = Extract(MapCarrier{})
+	assert.NotNil(t, err)
+	assert.NotNil(t, Inject(context.Background(), MapCarrier{}))
+}
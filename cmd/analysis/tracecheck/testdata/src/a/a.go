@@ -0,0 +1,146 @@
+package a
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/rfyiamcool/go-tracer"
+	"github.com/rfyiamcool/go-tracer/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func call(ctx context.Context) error { return nil }
+
+func discardContext(ctx context.Context) error {
+	span, _ := tracer.StartSpanFromContext(ctx, tracer.GetFunc()) // want `the context returned by StartSpanFromContext is discarded but ctx is used later`
+	defer span.Finish()
+
+	return call(ctx)
+}
+
+func discardContextLeaf(ctx context.Context) {
+	span, _ := tracer.StartSpanFromContext(ctx, tracer.GetFunc())
+	defer span.Finish()
+}
+
+type spanKey struct{}
+
+// the span is carried by the returned context, like the attempt spans of grpc.
+func discardContextCarried(ctx context.Context) context.Context {
+	_, span := otel.Start(ctx, "carried")
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func discardContextReturned(ctx context.Context) (context.Context, opentracing.Span) {
+	span, _ := tracer.StartSpanFromContext(ctx, tracer.GetFunc())
+	return ctx, span
+}
+
+func discardSpan(ctx context.Context) error {
+	ctx, _ = otel.Start(ctx, "discard") // want `the span returned by Start is discarded`
+	return ctx.Err()
+}
+
+func discardBoth(ctx context.Context) {
+	_, _ = otel.Start(ctx, "discard") // want `the span returned by Start is discarded`
+}
+
+// the span is taken from the returned context, like the hooks of redis.
+func discardSpanReturned(ctx context.Context) (context.Context, error) {
+	ctx, _ = otel.Start(ctx, "returned")
+	return ctx, nil
+}
+
+func discardSpanPassed(ctx context.Context) error {
+	ctx, _ = otel.Start(ctx, "passed")
+	return call(ctx)
+}
+
+type holder struct {
+	ctx context.Context
+}
+
+func discardSpanStored(ctx context.Context) *holder {
+	ctx, _ = otel.Start(ctx, "stored")
+	return &holder{ctx: ctx}
+}
+
+func deferFinish(ctx context.Context) error {
+	span, ctx := tracer.StartSpanFromContext(ctx, tracer.GetFunc())
+	defer span.Finish()
+
+	span.SetTag("k", "v")
+	return call(ctx)
+}
+
+func deferFuncEnd(ctx context.Context) (err error) {
+	ctx, span := otel.Start(ctx, "defer")
+	defer func() {
+		span.End()
+	}()
+
+	return call(ctx)
+}
+
+func missingFinish(ctx context.Context) error {
+	span, ctx := tracer.StartSpanFromContext(ctx, tracer.GetFunc()) // want `span.Finish is not called on all paths`
+	span.SetTag("k", "v")
+	if err := call(ctx); err != nil {
+		return err // want `this return statement may be reached without calling span.Finish`
+	}
+	span.Finish()
+	return nil
+}
+
+func missingEnd(ctx context.Context, tracer trace.Tracer) {
+	ctx, span := tracer.Start(ctx, "missing") // want `span.End is not called on all paths`
+	span.SetName("missing")
+	call(ctx)
+} // want `this return statement may be reached without calling span.End`
+
+func returnSpan(ctx context.Context) (context.Context, trace.Span) {
+	ctx, span := otel.Start(ctx, "return") // want `span.End is not called on all paths`
+	if ctx == nil {
+		return nil, nil // want `this return statement may be reached without calling span.End`
+	}
+	return ctx, span
+}
+
+func goroutine(ctx context.Context) {
+	go func() {
+		ctx, span := otel.Start(ctx, "goroutine")
+		defer span.End()
+
+		call(context.Background()) // want `context.Background\(\) drops the span of ctx in scope, use ctx instead`
+		call(ctx)
+	}()
+}
+
+func background() error {
+	return call(context.TODO())
+}
+
+func blank(_ context.Context) error {
+	if err := call(context.Background()); err != nil {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func spanFromContext(ctx context.Context) error {
+	span := opentracing.SpanFromContext(ctx)
+	span.SetTag("k", "v")
+
+	return call(context.Background()) // want `context.Background\(\) drops the span of ctx in scope, use ctx instead`
+}
+
+type server interface {
+	Shutdown(ctx context.Context) error
+}
+
+// the untraced function may use a new context on purpose.
+func shutdown(ctx context.Context, srv server) error {
+	<-ctx.Done()
+	return srv.Shutdown(context.Background())
+}
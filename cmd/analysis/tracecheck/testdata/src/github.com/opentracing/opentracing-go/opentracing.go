@@ -0,0 +1,16 @@
+package opentracing
+
+import "context"
+
+type Span interface {
+	Finish()
+	SetTag(key string, value interface{}) Span
+}
+
+func StartSpanFromContext(ctx context.Context, operationName string) (Span, context.Context) {
+	return nil, ctx
+}
+
+func SpanFromContext(ctx context.Context) Span {
+	return nil
+}
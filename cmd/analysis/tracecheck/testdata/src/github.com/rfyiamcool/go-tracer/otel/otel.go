@@ -0,0 +1,11 @@
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func Start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return ctx, nil
+}
@@ -0,0 +1,12 @@
+package trace
+
+import "context"
+
+type Span interface {
+	End()
+	SetName(name string)
+}
+
+type Tracer interface {
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
@@ -0,0 +1,461 @@
+// Package tracecheck defines an Analyzer that reports the common mistakes of tracing.
+//
+//	go install github.com/rfyiamcool/go-tracer/cmd/tracecheck@latest
+//	go vet -vettool=$(which tracecheck) ./...
+package tracecheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/ctrlflow"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/cfg"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const doc = `check for the common mistakes of tracing
+
+The tracecheck analysis reports:
+
+- the context returned with the span is discarded, but the origin ctx is used
+  later, so the child spans are attached to the parent of the span.
+- the span is not finished or ended on some paths.
+- context.Background() or context.TODO() is used where a traced ctx is in scope, in the
+  function which starts a span or takes the span from the context.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "tracecheck",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer, ctrlflow.Analyzer},
+	Run:      run,
+}
+
+// startFunc the result index of context and span, and the method to finish the span.
+type startFunc struct {
+	ctxIndex  int
+	spanIndex int
+	finish    string
+}
+
+var startFuncs = map[string]startFunc{
+	"github.com/rfyiamcool/go-tracer.StartSpanFromContext":                 {ctxIndex: 1, spanIndex: 0, finish: "Finish"},
+	"github.com/rfyiamcool/go-tracer.Start":                                {ctxIndex: 0, spanIndex: 1, finish: "End"},
+	"github.com/rfyiamcool/go-tracer/otel.Start":                           {ctxIndex: 0, spanIndex: 1, finish: "End"},
+	"github.com/rfyiamcool/go-tracer/otel.StartSpan":                       {ctxIndex: 0, spanIndex: 1, finish: "End"},
+	"github.com/opentracing/opentracing-go.StartSpanFromContext":           {ctxIndex: 1, spanIndex: 0, finish: "Finish"},
+	"github.com/opentracing/opentracing-go.StartSpanFromContextWithTracer": {ctxIndex: 1, spanIndex: 0, finish: "Finish"},
+	"(go.opentelemetry.io/otel/trace.Tracer).Start":                        {ctxIndex: 0, spanIndex: 1, finish: "End"},
+}
+
+// spanFromFuncs the functions which take the span from the context.
+var spanFromFuncs = map[string]bool{
+	"github.com/rfyiamcool/go-tracer.SpanFromContext":       true,
+	"github.com/rfyiamcool/go-tracer/otel.SpanFromContext":  true,
+	"github.com/opentracing/opentracing-go.SpanFromContext": true,
+	"go.opentelemetry.io/otel/trace.SpanFromContext":        true,
+	"go.opentelemetry.io/otel/trace.SpanContextFromContext": true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		runFunc(pass, n)
+	})
+
+	traced := make(map[ast.Node]bool)
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if push {
+			checkBackground(pass, n.(*ast.CallExpr), stack, traced)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// spanVar the span variable defined by stmt.
+type spanVar struct {
+	stmt   ast.Node
+	call   *ast.CallExpr
+	finish string
+}
+
+// runFunc check the spans started in the function, the nested function literals are checked
+// separately.
+func runFunc(pass *analysis.Pass, node ast.Node) {
+	var body *ast.BlockStmt
+	switch node := node.(type) {
+	case *ast.FuncDecl:
+		body = node.Body
+	case *ast.FuncLit:
+		body = node.Body
+	}
+	if body == nil {
+		return
+	}
+
+	spanVars := make(map[*types.Var]spanVar)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			if len(n.Rhs) == 1 {
+				checkStart(pass, body, n, n.Lhs, n.Rhs[0], spanVars)
+			}
+		case *ast.ValueSpec:
+			if len(n.Values) == 1 {
+				lhs := make([]ast.Expr, len(n.Names))
+				for i, name := range n.Names {
+					lhs[i] = name
+				}
+				checkStart(pass, body, n, lhs, n.Values[0], spanVars)
+			}
+		}
+		return true
+	})
+	if len(spanVars) == 0 {
+		return
+	}
+
+	cfgs := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
+	var (
+		g   *cfg.CFG
+		sig *types.Signature
+	)
+	switch node := node.(type) {
+	case *ast.FuncDecl:
+		g = cfgs.FuncDecl(node)
+		if obj, ok := pass.TypesInfo.Defs[node.Name].(*types.Func); ok {
+			sig = obj.Type().(*types.Signature)
+		}
+	case *ast.FuncLit:
+		g = cfgs.FuncLit(node)
+		if tv, ok := pass.TypesInfo.Types[node.Type]; ok {
+			sig, _ = tv.Type.(*types.Signature)
+		}
+	}
+	if g == nil || sig == nil {
+		return
+	}
+
+	for v, sv := range spanVars {
+		ret := unfinishedPath(pass, g, v, sv, sig)
+		if ret == nil {
+			continue
+		}
+
+		lineno := pass.Fset.Position(sv.stmt.Pos()).Line
+		pass.ReportRangef(sv.stmt, "%s.%s is not called on all paths, the span is never reported", v.Name(), sv.finish)
+
+		pos, end := ret.Pos(), ret.End()
+		// the synthetic return statement may overflow the file.
+		if pass.Fset.File(pos) != pass.Fset.File(end) {
+			end = pos
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:     pos,
+			End:     end,
+			Message: fmt.Sprintf("this return statement may be reached without calling %s.%s of the span started on line %d", v.Name(), sv.finish, lineno),
+		})
+	}
+}
+
+// checkStart report the discarded context and span of the start function, and collect the span variable.
+func checkStart(pass *analysis.Pass, body *ast.BlockStmt, stmt ast.Node, lhs []ast.Expr, rhs ast.Expr, spanVars map[*types.Var]spanVar) {
+	call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 || len(lhs) < 2 {
+		return
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok {
+		return
+	}
+	sf, ok := startFuncs[fn.FullName()]
+	if !ok {
+		return
+	}
+
+	if ctxID, ok := lhs[sf.ctxIndex].(*ast.Ident); ok && ctxID.Name == "_" {
+		// the span may be carried to others by hand, like context.WithValue(ctx, key, span).
+		spanID, ok := lhs[sf.spanIndex].(*ast.Ident)
+		carried := ok && spanID.Name != "_" && escapesAfter(pass, body, spanID, stmt)
+		if parent, ok := ast.Unparen(call.Args[0]).(*ast.Ident); ok && !carried && usedAfter(pass, body, parent, stmt) {
+			pass.ReportRangef(ctxID, "the context returned by %s is discarded but %s is used later, the child spans are attached to the parent of the span", fn.Name(), parent.Name)
+		}
+	}
+
+	spanID, ok := lhs[sf.spanIndex].(*ast.Ident)
+	if !ok {
+		return
+	}
+	if spanID.Name == "_" {
+		// the span may be taken from the context by others, like the hooks of redis.
+		if ctxID, ok := lhs[sf.ctxIndex].(*ast.Ident); !ok || !escapesAfter(pass, body, ctxID, stmt) {
+			pass.ReportRangef(spanID, "the span returned by %s is discarded, it's never reported", fn.Name())
+		}
+		return
+	}
+
+	v, ok := pass.TypesInfo.Defs[spanID].(*types.Var)
+	if !ok {
+		v, ok = pass.TypesInfo.Uses[spanID].(*types.Var)
+	}
+	if ok {
+		spanVars[v] = spanVar{stmt: stmt, call: call, finish: sf.finish}
+	}
+}
+
+// usedAfter reports whether the variable of ident is used after stmt in body.
+func usedAfter(pass *analysis.Pass, body *ast.BlockStmt, ident *ast.Ident, stmt ast.Node) bool {
+	obj := pass.TypesInfo.Uses[ident]
+	if obj == nil {
+		return false
+	}
+
+	var found bool
+	ast.Inspect(body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Pos() > stmt.End() && pass.TypesInfo.Uses[id] == obj {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// escapesAfter reports whether the variable of ident is returned, stored or passed to a function
+// after stmt in body.
+func escapesAfter(pass *analysis.Pass, body *ast.BlockStmt, ident *ast.Ident, stmt ast.Node) bool {
+	obj := pass.TypesInfo.ObjectOf(ident)
+	if obj == nil {
+		return false
+	}
+
+	var found bool
+	escapes := func(exprs []ast.Expr) {
+		for _, expr := range exprs {
+			if id, ok := ast.Unparen(expr).(*ast.Ident); ok && id.Pos() > stmt.End() && pass.TypesInfo.Uses[id] == obj {
+				found = true
+			}
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ReturnStmt:
+			escapes(n.Results)
+		case *ast.AssignStmt:
+			escapes(n.Rhs)
+		case *ast.ValueSpec:
+			escapes(n.Values)
+		case *ast.CallExpr:
+			escapes(n.Args)
+		case *ast.SendStmt:
+			escapes([]ast.Expr{n.Value})
+		case *ast.CompositeLit:
+			escapes(n.Elts)
+		case *ast.KeyValueExpr:
+			escapes([]ast.Expr{n.Value})
+		}
+		return !found
+	})
+	return found
+}
+
+// unfinishedPath finds a path through the CFG, from the statement which defines the span variable v
+// to a return statement, that doesn't finish v, the same as lostcancel. Passing v to others, like
+// return or assignment, is treated as finished.
+func unfinishedPath(pass *analysis.Pass, g *cfg.CFG, v *types.Var, sv spanVar, sig *types.Signature) *ast.ReturnStmt {
+	isNamedResult := tupleContains(sig.Results(), v)
+
+	uses := func(nodes []ast.Node) bool {
+		var found bool
+		for _, node := range nodes {
+			ast.Inspect(node, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.SelectorExpr:
+					// the method call like SetTag isn't a use.
+					if id, ok := n.X.(*ast.Ident); ok && pass.TypesInfo.Uses[id] == v {
+						found = n.Sel.Name == sv.finish
+						return false
+					}
+				case *ast.Ident:
+					if pass.TypesInfo.Uses[n] == v {
+						found = true
+					}
+				case *ast.ReturnStmt:
+					// the naked return returns the named results.
+					if n.Results == nil && isNamedResult {
+						found = true
+					}
+				}
+				return !found
+			})
+			if found {
+				return true
+			}
+		}
+		return false
+	}
+
+	memo := make(map[*cfg.Block]bool)
+	blockUses := func(b *cfg.Block) bool {
+		res, ok := memo[b]
+		if !ok {
+			res = uses(b.Nodes)
+			memo[b] = res
+		}
+		return res
+	}
+
+	// the defining block and the rest of statements.
+	var (
+		defblock *cfg.Block
+		rest     []ast.Node
+	)
+outer:
+	for _, b := range g.Blocks {
+		for i, n := range b.Nodes {
+			if n == sv.stmt {
+				defblock = b
+				rest = b.Nodes[i+1:]
+				break outer
+			}
+		}
+	}
+	if defblock == nil {
+		// unreachable statement.
+		return nil
+	}
+
+	if uses(rest) {
+		return nil
+	}
+	if ret := defblock.Return(); ret != nil {
+		return ret
+	}
+
+	seen := make(map[*cfg.Block]bool)
+	var search func(blocks []*cfg.Block) *ast.ReturnStmt
+	search = func(blocks []*cfg.Block) *ast.ReturnStmt {
+		for _, b := range blocks {
+			if seen[b] {
+				continue
+			}
+			seen[b] = true
+
+			if blockUses(b) {
+				continue
+			}
+			if ret := b.Return(); ret != nil {
+				return ret
+			}
+			if ret := search(b.Succs); ret != nil {
+				return ret
+			}
+		}
+		return nil
+	}
+	return search(defblock.Succs)
+}
+
+func tupleContains(tuple *types.Tuple, v *types.Var) bool {
+	for i := 0; i < tuple.Len(); i++ {
+		if tuple.At(i) == v {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBackground report context.Background() and context.TODO() in the traced function which
+// has the context.Context parameter, or nested in it. The function is traced if it starts a span
+// or takes the span from the context, the others may use a new context on purpose, like shutdown.
+func checkBackground(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node, traced map[ast.Node]bool) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "context" || (fn.Name() != "Background" && fn.Name() != "TODO") {
+		return
+	}
+
+	var inTraced bool
+	for i := len(stack) - 1; i >= 0; i-- {
+		var (
+			ftype *ast.FuncType
+			body  *ast.BlockStmt
+		)
+		switch n := stack[i].(type) {
+		case *ast.FuncDecl:
+			ftype, body = n.Type, n.Body
+		case *ast.FuncLit:
+			ftype, body = n.Type, n.Body
+		default:
+			continue
+		}
+
+		res, ok := traced[stack[i]]
+		if !ok {
+			res = isTraced(pass, body)
+			traced[stack[i]] = res
+		}
+		inTraced = inTraced || res
+
+		if name := contextParam(pass, ftype); name != "" {
+			if inTraced {
+				pass.ReportRangef(call, "context.%s() drops the span of %s in scope, use %s instead", fn.Name(), name, name)
+			}
+			return
+		}
+	}
+}
+
+// isTraced reports whether the function body calls the start functions or takes the span from
+// the context, the nested function literals are checked separately.
+func isTraced(pass *analysis.Pass, body *ast.BlockStmt) bool {
+	var found bool
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if fn, ok := typeutil.Callee(pass.TypesInfo, n).(*types.Func); ok {
+				name := fn.FullName()
+				_, start := startFuncs[name]
+				found = start || spanFromFuncs[name]
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// contextParam returns the name of the context.Context parameter.
+func contextParam(pass *analysis.Pass, ftype *ast.FuncType) string {
+	for _, field := range ftype.Params.List {
+		tv, ok := pass.TypesInfo.Types[field.Type]
+		if !ok || !isContext(tv.Type) {
+			continue
+		}
+		for _, name := range field.Names {
+			if name.Name != "_" {
+				return name.Name
+			}
+		}
+	}
+	return ""
+}
+
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
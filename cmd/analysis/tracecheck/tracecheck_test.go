@@ -0,0 +1,12 @@
+package tracecheck_test
+
+import (
+	"testing"
+
+	"github.com/rfyiamcool/go-tracer/cmd/analysis/tracecheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), tracecheck.Analyzer, "a")
+}
@@ -0,0 +1,14 @@
+// Command tracecheck runs the tracecheck analyzer, standalone or by go vet.
+//
+//	tracecheck ./...
+//	go vet -vettool=$(which tracecheck) ./...
+package main
+
+import (
+	"github.com/rfyiamcool/go-tracer/cmd/analysis/tracecheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(tracecheck.Analyzer)
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	modeOpentracing = "opentracing"
+	modeOtel        = "otel"
+
+	pathTracer    = "github.com/rfyiamcool/go-tracer"
+	pathOtel      = "github.com/rfyiamcool/go-tracer/otel"
+	pathExt       = "github.com/opentracing/opentracing-go/ext"
+	pathLog       = "github.com/opentracing/opentracing-go/log"
+	pathAttribute = "go.opentelemetry.io/otel/attribute"
+	pathCodes     = "go.opentelemetry.io/otel/codes"
+)
+
+type config struct {
+	Dir    string
+	Type   string
+	Mode   string
+	Name   string
+	Params []string
+}
+
+// generate load the package in cfg.Dir and returns the formatted source of the decorator.
+func generate(cfg config) ([]byte, error) {
+	if cfg.Mode != modeOpentracing && cfg.Mode != modeOtel {
+		return nil, fmt.Errorf("unknown mode %q, want %s or %s", cfg.Mode, modeOpentracing, modeOtel)
+	}
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes,
+		Dir:  cfg.Dir,
+	}, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, got %d", cfg.Dir, len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, pkg.Errors[0]
+	}
+
+	obj, ok := pkg.Types.Scope().Lookup(cfg.Type).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in %s", cfg.Type, pkg.PkgPath)
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not an interface", cfg.Type)
+	}
+
+	g := newGenerator(cfg, pkg.Types)
+	return g.generate(obj.Name(), iface)
+}
+
+type generator struct {
+	cfg     config
+	pkg     *types.Package
+	imports *importSet
+	params  map[string]bool
+
+	body bytes.Buffer
+}
+
+func newGenerator(cfg config, pkg *types.Package) *generator {
+	g := &generator{
+		cfg:     cfg,
+		pkg:     pkg,
+		imports: newImportSet(pkg),
+		params:  make(map[string]bool),
+	}
+	for _, p := range cfg.Params {
+		g.params[p] = true
+	}
+
+	// reserve the names of tracing packages before the packages of signatures.
+	if cfg.Mode == modeOtel {
+		g.imports.reserve(pathOtel, "otel")
+		g.imports.reserve(pathAttribute, "attribute")
+		g.imports.reserve(pathCodes, "codes")
+	} else {
+		g.imports.reserve(pathTracer, "tracer")
+		g.imports.reserve(pathExt, "ext")
+		g.imports.reserve(pathLog, "log")
+	}
+	g.imports.reserve("fmt", "fmt")
+	return g
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format, args...)
+}
+
+func (g *generator) generate(typeName string, iface *types.Interface) ([]byte, error) {
+	name := g.cfg.Name
+	if name == "" {
+		name = typeName + "WithTracing"
+	}
+	constructor := "New" + upperFirst(name)
+	if !isExported(typeName) {
+		constructor = "new" + upperFirst(name)
+	}
+
+	g.printf("// %s implements %s, the method with context.Context is traced.\n", name, typeName)
+	g.printf("type %s struct {\n\tbase %s\n}\n\n", name, typeName)
+	g.printf("var _ %s = &%s{}\n\n", typeName, name)
+	g.printf("// %s returns the decorator of base.\n", constructor)
+	g.printf("func %s(base %s) *%s {\n\treturn &%s{base: base}\n}\n", constructor, typeName, name, name)
+
+	for i := 0; i < iface.NumMethods(); i++ {
+		if err := g.method(typeName, name, iface.Method(i)); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by tracegen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg.Name())
+	g.imports.write(&buf)
+	buf.Write(g.body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated source: %v\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+type variable struct {
+	name string // the original name, used as tag key
+	id   string // the identifier in generated code
+	typ  types.Type
+	expr string
+}
+
+func (g *generator) method(typeName, recvName string, fn *types.Func) error {
+	sig := fn.Type().(*types.Signature)
+
+	// type expressions first, the imports are allocated before naming the variables.
+	params := make([]variable, sig.Params().Len())
+	for i := range params {
+		v := sig.Params().At(i)
+		params[i] = variable{name: v.Name(), typ: v.Type(), expr: types.TypeString(v.Type(), g.imports.qualifier)}
+		if sig.Variadic() && i == len(params)-1 {
+			params[i].expr = "..." + types.TypeString(v.Type().(*types.Slice).Elem(), g.imports.qualifier)
+		}
+	}
+	results := make([]variable, sig.Results().Len())
+	for i := range results {
+		v := sig.Results().At(i)
+		results[i] = variable{typ: v.Type(), expr: types.TypeString(v.Type(), g.imports.qualifier)}
+	}
+
+	used := map[string]bool{"d": true, "span": true}
+	for name := range g.imports.names {
+		used[name] = true
+	}
+	ctxIndex := -1
+	for i := range params {
+		params[i].id = uniqueName(used, params[i].name, "p"+strconv.Itoa(i))
+		if ctxIndex < 0 && isContext(params[i].typ) {
+			ctxIndex = i
+		}
+	}
+	errIndex := -1
+	if n := len(results); n > 0 && isError(results[n-1].typ) {
+		errIndex = n - 1
+	}
+
+	var (
+		paramList  []string
+		args       []string
+		resultList []string
+	)
+	for i, p := range params {
+		paramList = append(paramList, p.id+" "+p.expr)
+		arg := p.id
+		if sig.Variadic() && i == len(params)-1 {
+			arg += "..."
+		}
+		args = append(args, arg)
+	}
+
+	traced := ctxIndex >= 0
+	for i := range results {
+		// only the traced method with error needs the named results.
+		if !traced || errIndex < 0 {
+			resultList = append(resultList, results[i].expr)
+			continue
+		}
+		results[i].id = uniqueName(used, "", "r"+strconv.Itoa(i))
+		if i == errIndex {
+			results[i].id = uniqueName(used, "err", "err")
+		}
+		resultList = append(resultList, results[i].id+" "+results[i].expr)
+	}
+
+	resultExpr := strings.Join(resultList, ", ")
+	if len(resultList) > 1 || (len(resultList) == 1 && results[0].id != "") {
+		resultExpr = "(" + resultExpr + ")"
+	}
+
+	g.printf("\n// %s implements %s.%s.\n", fn.Name(), typeName, fn.Name())
+	g.printf("func (d *%s) %s(%s) %s {\n", recvName, fn.Name(), strings.Join(paramList, ", "), resultExpr)
+	if traced {
+		g.startSpan(typeName+"."+fn.Name(), params[ctxIndex].id, errIndex >= 0, results)
+		g.tagParams(fn.Name(), params)
+	}
+
+	call := fmt.Sprintf("d.base.%s(%s)", fn.Name(), strings.Join(args, ", "))
+	if len(results) > 0 {
+		g.printf("\treturn %s\n}\n", call)
+	} else {
+		g.printf("\t%s\n}\n", call)
+	}
+	return nil
+}
+
+func (g *generator) startSpan(spanName, ctx string, hasErr bool, results []variable) {
+	var errID string
+	if hasErr {
+		errID = results[len(results)-1].id
+	}
+
+	if g.cfg.Mode == modeOtel {
+		g.printf("\t%s, span := %s.Start(%s, %q)\n", ctx, g.imports.use(pathOtel), ctx, spanName)
+		if !hasErr {
+			g.printf("\tdefer span.End()\n\n")
+			return
+		}
+		g.printf("\tdefer func() {\n\t\tif %s != nil {\n", errID)
+		g.printf("\t\t\tspan.RecordError(%s)\n", errID)
+		g.printf("\t\t\tspan.SetStatus(%s.Error, %s.Error())\n", g.imports.use(pathCodes), errID)
+		g.printf("\t\t}\n\t\tspan.End()\n\t}()\n\n")
+		return
+	}
+
+	g.printf("\tspan, %s := %s.StartSpanFromContext(%s, %q)\n", ctx, g.imports.use(pathTracer), ctx, spanName)
+	if !hasErr {
+		g.printf("\tdefer span.Finish()\n\n")
+		return
+	}
+	g.printf("\tdefer func() {\n\t\tif %s != nil {\n", errID)
+	g.printf("\t\t\t%s.Error.Set(span, true)\n", g.imports.use(pathExt))
+	g.printf("\t\t\tspan.LogFields(%s.Error(%s))\n", g.imports.use(pathLog), errID)
+	g.printf("\t\t}\n\t\tspan.Finish()\n\t}()\n\n")
+}
+
+func (g *generator) tagParams(method string, params []variable) {
+	var tagged bool
+	for _, p := range params {
+		if p.name == "" || !(g.params[p.name] || g.params[method+"."+p.name]) {
+			continue
+		}
+		tagged = true
+		if g.cfg.Mode == modeOtel {
+			g.printf("\tspan.SetAttributes(%s)\n", g.attribute(p))
+		} else {
+			g.printf("\tspan.SetTag(%q, %s)\n", p.name, p.id)
+		}
+	}
+	if tagged {
+		g.printf("\n")
+	}
+}
+
+// attribute returns the attribute expression by the underlying basic type, others are formatted by fmt.
+func (g *generator) attribute(p variable) string {
+	attribute := g.imports.use(pathAttribute)
+	convert := func(fn, typ string) string {
+		if b, ok := p.typ.(*types.Basic); ok && b.Name() == typ {
+			return fmt.Sprintf("%s.%s(%q, %s)", attribute, fn, p.name, p.id)
+		}
+		return fmt.Sprintf("%s.%s(%q, %s(%s))", attribute, fn, p.name, typ, p.id)
+	}
+
+	if b, ok := p.typ.Underlying().(*types.Basic); ok {
+		switch info := b.Info(); {
+		case info&types.IsBoolean != 0:
+			return convert("Bool", "bool")
+		case info&types.IsString != 0:
+			return convert("String", "string")
+		case info&types.IsInteger != 0:
+			return convert("Int64", "int64")
+		case info&types.IsFloat != 0:
+			return convert("Float64", "float64")
+		}
+	}
+	return fmt.Sprintf("%s.String(%q, %s.Sprint(%s))", attribute, p.name, g.imports.use("fmt"), p.id)
+}
+
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// uniqueName returns name if it's valid and not used, otherwise the fallback with number suffix.
+func uniqueName(used map[string]bool, name, fallback string) string {
+	if name == "" || name == "_" {
+		name = fallback
+	}
+	id := name
+	for i := 1; used[id]; i++ {
+		id = name + strconv.Itoa(i)
+	}
+	used[id] = true
+	return id
+}
+
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+func isExported(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(r)
+}
+
+// importSet allocates the package names of the generated file, the name doesn't conflict with
+// the identifiers of the package scope.
+type importSet struct {
+	pkg   *types.Package
+	paths map[string]string // path => name
+	names map[string]bool
+	used  map[string]bool // path
+}
+
+func newImportSet(pkg *types.Package) *importSet {
+	s := &importSet{
+		pkg:   pkg,
+		paths: make(map[string]string),
+		names: make(map[string]bool),
+		used:  make(map[string]bool),
+	}
+	for _, name := range pkg.Scope().Names() {
+		s.names[name] = true
+	}
+	return s
+}
+
+func (s *importSet) reserve(path, name string) string {
+	if n, ok := s.paths[path]; ok {
+		return n
+	}
+	n := name
+	for i := 1; s.names[n]; i++ {
+		n = name + strconv.Itoa(i)
+	}
+	s.paths[path] = n
+	s.names[n] = true
+	return n
+}
+
+func (s *importSet) use(path string) string {
+	s.used[path] = true
+	return s.paths[path]
+}
+
+func (s *importSet) qualifier(pkg *types.Package) string {
+	if pkg == s.pkg {
+		return ""
+	}
+	name := s.reserve(pkg.Path(), pkg.Name())
+	s.used[pkg.Path()] = true
+	return name
+}
+
+func (s *importSet) write(buf *bytes.Buffer) {
+	var std, others []string
+	for path := range s.used {
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			others = append(others, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	if len(std)+len(others) == 0 {
+		return
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+
+	buf.WriteString("import (\n")
+	for i, group := range [][]string{std, others} {
+		if i > 0 && len(std) > 0 && len(others) > 0 {
+			buf.WriteString("\n")
+		}
+		for _, path := range group {
+			name := s.paths[path]
+			if name == defaultName(path) {
+				fmt.Fprintf(buf, "\t%q\n", path)
+			} else {
+				fmt.Fprintf(buf, "\t%s %q\n", name, path)
+			}
+		}
+	}
+	buf.WriteString(")\n\n")
+}
+
+// defaultName the last element of path, the name of package is unknown without loading it.
+func defaultName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// update the golden files by:
+//
+//	go run . -type UserRepo -params id,Find.name,status,since -output userrepo_trace.golden ./testdata/repo
+//	go run . -type UserRepo -mode otel -name UserRepoWithOtel -output userrepo_otel.golden -params id,Find.name,status,since ./testdata/repo
+func TestGenerate(t *testing.T) {
+	cases := []struct {
+		cfg    config
+		golden string
+	}{
+		{
+			cfg:    config{Type: "UserRepo", Mode: modeOpentracing},
+			golden: "userrepo_trace.golden",
+		},
+		{
+			cfg:    config{Type: "UserRepo", Mode: modeOtel, Name: "UserRepoWithOtel"},
+			golden: "userrepo_otel.golden",
+		},
+	}
+
+	for _, c := range cases {
+		c.cfg.Dir = filepath.Join("testdata", "repo")
+		c.cfg.Params = []string{"id", "Find.name", "status", "since"}
+
+		src, err := generate(c.cfg)
+		assert.Nil(t, err)
+
+		want, err := ioutil.ReadFile(filepath.Join("testdata", "repo", c.golden))
+		assert.Nil(t, err)
+		assert.Equal(t, string(want), string(src))
+	}
+}
+
+func TestGenerateError(t *testing.T) {
+	dir := filepath.Join("testdata", "repo")
+
+	_, err := generate(config{Dir: dir, Type: "UserRepo", Mode: "jaeger"})
+	assert.NotNil(t, err)
+
+	_, err = generate(config{Dir: dir, Type: "Missing", Mode: modeOtel})
+	assert.EqualError(t, err, "type Missing not found in github.com/rfyiamcool/go-tracer/cmd/tracegen/testdata/repo")
+
+	_, err = generate(config{Dir: dir, Type: "User", Mode: modeOtel})
+	assert.EqualError(t, err, "type User is not an interface")
+}
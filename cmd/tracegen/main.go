@@ -0,0 +1,81 @@
+// Command tracegen generates a decorator of the interface, every method with context.Context
+// starts a span, tags the selected parameters, records the returned error and finishes the span.
+//
+//	//go:generate go run github.com/rfyiamcool/go-tracer/cmd/tracegen@latest -type UserRepo -params id,GetUser.name
+//	//go:generate go run github.com/rfyiamcool/go-tracer/cmd/tracegen@latest -type UserRepo -mode otel
+//
+// The decorator is written to <type>_trace.go in the same package, use it like:
+//
+//	repo = NewUserRepoWithTracing(repo)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeName = flag.String("type", "", "interface type name, required")
+	mode     = flag.String("mode", modeOpentracing, "tracer api, opentracing or otel")
+	params   = flag.String("params", "", "comma-separated parameters to tag, name for all methods or Method.name")
+	output   = flag.String("output", "", "output file name, default <type>_trace.go")
+	name     = flag.String("name", "", "decorator type name, default <type>WithTracing")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: tracegen -type T [flags] [dir]\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if *typeName == "" {
+		usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	cfg := config{
+		Dir:    dir,
+		Type:   *typeName,
+		Mode:   *mode,
+		Name:   *name,
+		Params: splitList(*params),
+	}
+	src, err := generate(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tracegen: %v\n", err)
+		os.Exit(1)
+	}
+
+	out := *output
+	if out == "" {
+		out = strings.ToLower(*typeName) + "_trace.go"
+	}
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out)
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "tracegen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
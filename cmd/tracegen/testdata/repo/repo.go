@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+type User struct {
+	ID   int64
+	Name string
+}
+
+type Status int
+
+// UserRepo the interface to decorate.
+type UserRepo interface {
+	Get(ctx context.Context, id int64) (*User, error)
+	Find(ctx context.Context, name string, status Status, since time.Time) ([]*User, int, error)
+	Delete(context.Context, ...int64) error
+	Touch(ctx context.Context, user *User)
+	Close() error
+}
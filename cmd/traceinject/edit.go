@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+// editor edits the source by the offsets, the comments and layout are kept as they are, the
+// result is formatted by gofmt.
+type editor struct {
+	fset  *token.FileSet
+	src   []byte
+	edits []edit
+}
+
+func (e *editor) offset(pos token.Pos) int {
+	return e.fset.Position(pos).Offset
+}
+
+// skipSpace returns the position of the next non-space character.
+func (e *editor) skipSpace(pos token.Pos) token.Pos {
+	off := e.offset(pos)
+	n := 0
+	for off+n < len(e.src) && strings.ContainsRune(" \t\r\n", rune(e.src[off+n])) {
+		n++
+	}
+	return pos + token.Pos(n)
+}
+
+func (e *editor) insert(pos token.Pos, text string) {
+	off := e.offset(pos)
+	e.edits = append(e.edits, edit{start: off, end: off, text: text})
+}
+
+func (e *editor) replace(start, end token.Pos, text string) {
+	e.edits = append(e.edits, edit{start: e.offset(start), end: e.offset(end), text: text})
+}
+
+func (e *editor) apply() ([]byte, error) {
+	sort.SliceStable(e.edits, func(i, j int) bool {
+		return e.edits[i].start < e.edits[j].start
+	})
+
+	var (
+		buf  bytes.Buffer
+		last int
+	)
+	for _, ed := range e.edits {
+		buf.Write(e.src[last:ed.start])
+		buf.WriteString(ed.text)
+		last = ed.end
+	}
+	buf.Write(e.src[last:])
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const (
+	modeOpentracing = "opentracing"
+	modeOtel        = "otel"
+
+	pathTracer = "github.com/rfyiamcool/go-tracer"
+	pathOtel   = "github.com/rfyiamcool/go-tracer/otel"
+)
+
+// rewriter insert or remove the span statements at the beginning of the functions which take
+// context.Context as first parameter.
+//
+// opentracing:
+//
+//	span, ctx := tracer.StartSpanFromContext(ctx, tracer.GetFunc())
+//	defer span.Finish()
+//
+// otel:
+//
+//	ctx, span := otel.Start(ctx, "(*Service).GetUser")
+//	defer span.End()
+type rewriter struct {
+	mode    string
+	include []string
+	exclude []string
+	remove  bool
+}
+
+func (r *rewriter) importPath() string {
+	if r.mode == modeOtel {
+		return pathOtel
+	}
+	return pathTracer
+}
+
+// rewrite returns the formatted source and true if the source is changed.
+func (r *rewriter) rewrite(filename string, src []byte) ([]byte, bool, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, false, err
+	}
+	if ast.IsGenerated(file) {
+		return src, false, nil
+	}
+
+	contextName, ok := importName(file, "context")
+	if !ok {
+		return src, false, nil
+	}
+	pkgName, imported := importName(file, r.importPath())
+	if !imported {
+		pkgName = r.newImportName(file)
+	}
+
+	e := &editor{fset: fset, src: src}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || !hasContextParam(fn, contextName) || !r.match(fn) {
+			continue
+		}
+		if funcDeclares(fn, pkgName) {
+			continue // the package is shadowed by the receiver, parameters or results.
+		}
+
+		if r.remove {
+			r.removeSpan(e, fn, pkgName)
+		} else {
+			r.injectSpan(e, fn, pkgName)
+		}
+	}
+	if len(e.edits) == 0 {
+		return src, false, nil
+	}
+
+	if !r.remove && !imported {
+		addImport(e, file, pkgName, r.importPath())
+	}
+	out, err := e.apply()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if r.remove && imported {
+		// the import is deleted if it's not used by others.
+		if out, err = deleteUnusedImport(filename, out, r.importPath()); err != nil {
+			return nil, false, err
+		}
+	}
+	return out, true, nil
+}
+
+// match the function name without pointer, like Service.GetUser, by the include and exclude patterns.
+func (r *rewriter) match(fn *ast.FuncDecl) bool {
+	name := funcName(fn, false)
+	for _, pattern := range r.exclude {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+	if len(r.include) == 0 {
+		return true
+	}
+	for _, pattern := range r.include {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// injectSpan skip the function which has been traced or declares span.
+func (r *rewriter) injectSpan(e *editor, fn *ast.FuncDecl, pkgName string) {
+	if usesIdent(fn.Body, "span") || funcDeclares(fn, "span") {
+		return
+	}
+	ctx, ok := bindContextParam(e, fn)
+	if !ok {
+		return
+	}
+
+	var stmts string
+	if r.mode == modeOtel {
+		stmts = fmt.Sprintf("%s, span := %s.Start(%s, %q)\ndefer span.End()", ctx, pkgName, ctx, funcName(fn, true))
+	} else {
+		stmts = fmt.Sprintf("span, %s := %s.StartSpanFromContext(%s, %s.GetFunc())\ndefer span.Finish()", ctx, pkgName, ctx, pkgName)
+	}
+	if len(fn.Body.List) > 0 {
+		// keep a blank line before the origin statements.
+		stmts += "\n"
+	}
+	e.insert(fn.Body.Lbrace+1, "\n"+stmts+"\n")
+}
+
+// removeSpan only remove the statements in the form of injectSpan.
+func (r *rewriter) removeSpan(e *editor, fn *ast.FuncDecl, pkgName string) {
+	list := fn.Body.List
+	if len(list) < 2 {
+		return
+	}
+	assign, ok := list[0].(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+		return
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 {
+		return
+	}
+
+	var (
+		spanIndex = 0
+		startFunc = "StartSpanFromContext"
+		endFunc   = "Finish"
+	)
+	if r.mode == modeOtel {
+		spanIndex, startFunc, endFunc = 1, "Start", "End"
+		if lit, ok := call.Args[1].(*ast.BasicLit); !ok || lit.Kind != token.STRING {
+			return
+		}
+	} else if !isCall(call.Args[1], pkgName, "GetFunc") {
+		return
+	}
+
+	ctx, ok := assign.Lhs[1-spanIndex].(*ast.Ident)
+	if !ok || !isIdent(assign.Lhs[spanIndex], "span") || !isIdent(call.Args[0], ctx.Name) || !isSelector(call.Fun, pkgName, startFunc) {
+		return
+	}
+	deferStmt, ok := list[1].(*ast.DeferStmt)
+	if !ok || !isCall(deferStmt.Call, "span", endFunc) {
+		return
+	}
+
+	// the blank line after the statements is removed too.
+	e.replace(list[0].Pos(), e.skipSpace(list[1].End()), "")
+}
+
+// newImportName returns the package name of the tracer import, alias it if the name is used by
+// another import, declared in the package scope or by the functions.
+func (r *rewriter) newImportName(file *ast.File) string {
+	name := defaultImportName(r.importPath())
+	alias := "gotracer"
+	if r.mode == modeOtel {
+		alias = "tracerotel"
+	}
+
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if n, _ := importName(file, p); n == name {
+			return alias
+		}
+	}
+	if fileDeclares(file, name) {
+		return alias
+	}
+	return name
+}
+
+// fileDeclares reports whether name is declared in the package scope of file, or by the receiver,
+// parameters or results of a function.
+func fileDeclares(file *ast.File, name string) bool {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if (decl.Recv == nil && decl.Name.Name == name) || funcDeclares(decl, name) {
+				return true
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.ValueSpec:
+					for _, ident := range spec.Names {
+						if ident.Name == name {
+							return true
+						}
+					}
+				case *ast.TypeSpec:
+					if spec.Name.Name == name {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// funcDeclares reports whether name is declared by the receiver, type parameters, parameters or
+// results of fn.
+func funcDeclares(fn *ast.FuncDecl, name string) bool {
+	for _, list := range []*ast.FieldList{fn.Recv, fn.Type.TypeParams, fn.Type.Params, fn.Type.Results} {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			for _, ident := range field.Names {
+				if ident.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func hasContextParam(fn *ast.FuncDecl, contextName string) bool {
+	params := fn.Type.Params.List
+	return len(params) > 0 && isSelector(params[0].Type, contextName, "Context")
+}
+
+// bindContextParam returns the name of first parameter, the unnamed or blank parameter is named
+// to ctx, returns false if ctx is used by others.
+func bindContextParam(e *editor, fn *ast.FuncDecl) (string, bool) {
+	params := fn.Type.Params.List
+	if len(params[0].Names) > 0 && params[0].Names[0].Name != "_" {
+		return params[0].Names[0].Name, true
+	}
+	if usesIdent(fn.Type, "ctx") || usesIdent(fn.Body, "ctx") {
+		return "", false
+	}
+
+	if len(params[0].Names) > 0 {
+		ident := params[0].Names[0]
+		e.replace(ident.Pos(), ident.End(), "ctx")
+		return "ctx", true
+	}
+	for i, field := range params {
+		name := "_ "
+		if i == 0 {
+			name = "ctx "
+		}
+		e.insert(field.Type.Pos(), name)
+	}
+	return "ctx", true
+}
+
+// funcName returns Service.GetUser, the pointer receiver is (*Service).GetUser if withPointer.
+func funcName(fn *ast.FuncDecl, withPointer bool) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	typ := fn.Recv.List[0].Type
+	star, pointer := typ.(*ast.StarExpr)
+	if pointer {
+		typ = star.X
+	}
+	// generic receiver, T[K]
+	switch t := typ.(type) {
+	case *ast.IndexExpr:
+		typ = t.X
+	case *ast.IndexListExpr:
+		typ = t.X
+	}
+
+	var recv string
+	if ident, ok := typ.(*ast.Ident); ok {
+		recv = ident.Name
+	}
+	if pointer && withPointer {
+		recv = "(*" + recv + ")"
+	}
+	return recv + "." + fn.Name.Name
+}
+
+// importName returns the name of the import path in file.
+func importName(file *ast.File, importPath string) (string, bool) {
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if p != importPath {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name, true
+		}
+		return defaultImportName(p), true
+	}
+	return "", false
+}
+
+// addImport append the import to the last group of the import declaration, or a new group if the
+// last group is standard library.
+func addImport(e *editor, file *ast.File, name, importPath string) {
+	spec := strconv.Quote(importPath)
+	if name != defaultImportName(importPath) {
+		spec = name + " " + spec
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if !gen.Lparen.IsValid() {
+			e.insert(gen.End(), "\n\nimport "+spec)
+			return
+		}
+
+		last := gen.Specs[len(gen.Specs)-1].(*ast.ImportSpec)
+		p, _ := strconv.Unquote(last.Path.Value)
+		sep := "\n"
+		if isStdImport(p) {
+			sep = "\n\n"
+		}
+		e.insert(last.End(), sep+spec)
+		return
+	}
+}
+
+// deleteUnusedImport delete the import of path if the package isn't used.
+func deleteUnusedImport(filename string, src []byte, importPath string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if astutil.UsesImport(file, importPath) {
+		return src, nil
+	}
+
+	e := &editor{fset: fset, src: src}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ispec := spec.(*ast.ImportSpec)
+			if p, _ := strconv.Unquote(ispec.Path.Value); p != importPath {
+				continue
+			}
+			if len(gen.Specs) == 1 {
+				e.replace(gen.Pos(), gen.End(), "")
+			} else {
+				e.replace(ispec.Pos(), ispec.End(), "")
+			}
+		}
+	}
+	return e.apply()
+}
+
+func isStdImport(importPath string) bool {
+	return !strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".")
+}
+
+// defaultImportName the package name of go-tracer is tracer, others are guessed by the last element.
+func defaultImportName(importPath string) string {
+	if importPath == pathTracer {
+		return "tracer"
+	}
+	return importPath[strings.LastIndex(importPath, "/")+1:]
+}
+
+func usesIdent(node ast.Node, name string) bool {
+	var found bool
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func isSelector(expr ast.Expr, x, sel string) bool {
+	s, ok := expr.(*ast.SelectorExpr)
+	return ok && isIdent(s.X, x) && s.Sel.Name == sel
+}
+
+func isCall(expr ast.Expr, x, sel string) bool {
+	call, ok := expr.(*ast.CallExpr)
+	return ok && len(call.Args) == 0 && isSelector(call.Fun, x, sel)
+}
+
+func validMode(mode string) error {
+	if mode != modeOpentracing && mode != modeOtel {
+		return fmt.Errorf("unknown mode %q, want %s or %s", mode, modeOpentracing, modeOtel)
+	}
+	return nil
+}
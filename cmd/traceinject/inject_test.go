@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriter(t *testing.T) {
+	for _, name := range []string{"service", "collision"} {
+		src, err := ioutil.ReadFile(filepath.Join("testdata", name+".input"))
+		assert.Nil(t, err)
+
+		for _, mode := range []string{modeOpentracing, modeOtel} {
+			r := &rewriter{mode: mode, exclude: []string{"health"}}
+			injected, changed, err := r.rewrite("service.go", src)
+			assert.Nil(t, err)
+			assert.True(t, changed)
+
+			golden, err := ioutil.ReadFile(filepath.Join("testdata", name+"."+mode+".golden"))
+			assert.Nil(t, err)
+			assert.Equal(t, string(golden), string(injected))
+
+			// idempotent
+			_, changed, err = r.rewrite("service.go", injected)
+			assert.Nil(t, err)
+			assert.False(t, changed)
+
+			// the blank and unnamed context parameters are kept as ctx.
+			r.remove = true
+			removed, changed, err := r.rewrite("service.go", injected)
+			assert.Nil(t, err)
+			assert.True(t, changed)
+
+			removedGolden, err := ioutil.ReadFile(filepath.Join("testdata", name+".removed.golden"))
+			assert.Nil(t, err)
+			assert.Equal(t, string(removedGolden), string(removed))
+		}
+	}
+}
+
+func TestRewriterInclude(t *testing.T) {
+	src, err := ioutil.ReadFile(filepath.Join("testdata", "service.input"))
+	assert.Nil(t, err)
+
+	r := &rewriter{mode: modeOpentracing, include: []string{"Service.*"}, exclude: []string{"Service.load"}}
+	out, changed, err := r.rewrite("service.go", src)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Contains(t, string(out), "func (s *Service) GetUser(ctx context.Context, id int) error {\n\tspan, ctx := tracer.StartSpanFromContext(ctx, tracer.GetFunc())")
+	assert.Contains(t, string(out), "func (s Service) load(_ context.Context, id int) error {\n\treturn nil")
+	assert.Contains(t, string(out), "func notify(context.Context, string) {}")
+}
+
+func TestRewriterImportAlias(t *testing.T) {
+	src := []byte(`package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+)
+
+func handle(ctx context.Context) {
+	otel.GetTracerProvider()
+}
+`)
+
+	r := &rewriter{mode: modeOtel}
+	out, changed, err := r.rewrite("service.go", src)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Contains(t, string(out), "\ttracerotel \"github.com/rfyiamcool/go-tracer/otel\"\n\t\"go.opentelemetry.io/otel\"\n")
+	assert.Contains(t, string(out), "ctx, span := tracerotel.Start(ctx, \"handle\")")
+
+	r.remove = true
+	out, changed, err = r.rewrite("service.go", out)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, string(src), string(out))
+}
+
+func TestRewriterShadowedImport(t *testing.T) {
+	src := []byte(`package service
+
+import (
+	"context"
+
+	tracer "github.com/rfyiamcool/go-tracer"
+)
+
+var _ = tracer.GetFunc
+
+func Run(ctx context.Context, tracer string) {}
+`)
+
+	// the imported package can't be aliased, the function is skipped.
+	r := &rewriter{mode: modeOpentracing}
+	_, changed, err := r.rewrite("service.go", src)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+}
@@ -0,0 +1,106 @@
+// Command traceinject inserts the span statements at the beginning of the functions which take
+// context.Context as first parameter, and rebinds ctx to the span context.
+//
+//	traceinject -w ./service
+//	traceinject -w -mode otel -include 'Service.*' -exclude 'Service.health*' ./service
+//	traceinject -w --remove ./service
+//
+// The traced functions are skipped, so it's safe to run again after the code changes, the
+// --remove mode only removes the statements in the inserted form. The _test.go and generated
+// files are not rewritten.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	mode    = flag.String("mode", modeOpentracing, "tracer api, opentracing or otel")
+	include = flag.String("include", "", "comma-separated patterns of function names to rewrite, like Service.* or handle*")
+	exclude = flag.String("exclude", "", "comma-separated patterns of function names to skip")
+	remove  = flag.Bool("remove", false, "remove the inserted span statements")
+	write   = flag.Bool("w", false, "write result to source file instead of stdout")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: traceinject [flags] [dir ...]\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if err := validMode(*mode); err != nil {
+		fmt.Fprintf(os.Stderr, "traceinject: %v\n", err)
+		os.Exit(2)
+	}
+
+	r := &rewriter{
+		mode:    *mode,
+		include: splitList(*include),
+		exclude: splitList(*exclude),
+		remove:  *remove,
+	}
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	for _, dir := range dirs {
+		if err := rewriteDir(r, dir); err != nil {
+			fmt.Fprintf(os.Stderr, "traceinject: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func rewriteDir(r *rewriter, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		filename := filepath.Join(dir, name)
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		out, changed, err := r.rewrite(filename, src)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+
+		if !*write {
+			os.Stdout.Write(out)
+			continue
+		}
+		if err := ioutil.WriteFile(filename, out, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,136 @@
+// Package gormtrace trace gorm by opentracing, the spans are tagged with table, rows affected and statement.
+package gormtrace
+
+import (
+	"errors"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/rfyiamcool/go-tracer/internal/sqlhook"
+	"gorm.io/gorm"
+)
+
+const spanKey = "go-tracer:span"
+
+type Plugin struct {
+	tracer opentracing.Tracer
+
+	vars bool
+	tags opentracing.Tags
+}
+
+var _ gorm.Plugin = &Plugin{}
+
+type Option func(*Plugin)
+
+// WithVars tag the statement with variables, the variables are redacted by default.
+func WithVars() Option {
+	return func(p *Plugin) {
+		p.vars = true
+	}
+}
+
+// WithDBType tag db.type, like mysql, postgres. default: sql
+func WithDBType(name string) Option {
+	return func(p *Plugin) {
+		p.tags[string(ext.DBType)] = name
+	}
+}
+
+// WithInstance tag db.instance by the database name.
+func WithInstance(name string) Option {
+	return func(p *Plugin) {
+		p.tags[string(ext.DBInstance)] = name
+	}
+}
+
+// NewPlugin creates a new gorm plugin which collect spans using the provided tracer,
+// use the global tracer when tracer is nil.
+//
+//	db, err := gorm.Open(mysql.Open(dsn))
+//	db.Use(gormtrace.NewPlugin(tracer.GetTracer(), gormtrace.WithDBType("mysql")))
+//	db.WithContext(ctx).First(&user)
+func NewPlugin(tracer opentracing.Tracer, opts ...Option) *Plugin {
+	if tracer == nil {
+		tracer = opentracing.GlobalTracer()
+	}
+
+	p := &Plugin{
+		tracer: tracer,
+		tags:   opentracing.Tags{string(ext.DBType): "sql", string(ext.Component): "gorm"},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *Plugin) Name() string {
+	return "go-tracer:gorm"
+}
+
+// Initialize register the before and after callbacks of create, query, update, delete, row and raw.
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	callbacks := []struct {
+		before, after registerer
+		op            string
+	}{
+		{cb.Create().Before("gorm:create"), cb.Create().After("gorm:create"), "create"},
+		{cb.Query().Before("gorm:query"), cb.Query().After("gorm:query"), "query"},
+		{cb.Update().Before("gorm:update"), cb.Update().After("gorm:update"), "update"},
+		{cb.Delete().Before("gorm:delete"), cb.Delete().After("gorm:delete"), "delete"},
+		{cb.Row().Before("gorm:row"), cb.Row().After("gorm:row"), "row"},
+		{cb.Raw().Before("gorm:raw"), cb.Raw().After("gorm:raw"), "raw"},
+	}
+
+	for _, c := range callbacks {
+		if err := c.before.Register("go-tracer:before_"+c.op, p.before("gorm."+c.op)); err != nil {
+			return err
+		}
+		if err := c.after.Register("go-tracer:after_"+c.op, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerer is the callback of gorm processor.
+type registerer interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+func (p *Plugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		span, ctx := opentracing.StartSpanFromContextWithTracer(db.Statement.Context, p.tracer, operation, p.tags, ext.SpanKindRPCClient)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *Plugin) after(db *gorm.DB) {
+	val, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := val.(opentracing.Span)
+	defer span.Finish()
+
+	span.SetTag("db.table", db.Statement.Table)
+	span.SetTag("db.rows_affected", db.Statement.RowsAffected)
+	ext.DBStatement.Set(span, p.statement(db))
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		ext.Error.Set(span, true)
+		span.LogFields(log.String("event", "error"), log.String("message", db.Error.Error()))
+	}
+}
+
+func (p *Plugin) statement(db *gorm.DB) string {
+	query := db.Statement.SQL.String()
+	if p.vars {
+		return db.Dialector.Explain(query, db.Statement.Vars...)
+	}
+	return sqlhook.Redact(query)
+}
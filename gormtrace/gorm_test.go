@@ -0,0 +1,56 @@
+package gormtrace
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/rfyiamcool/go-tracer/internal/sqlhook/sqltest"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type user struct {
+	ID   int64
+	Name string
+}
+
+func TestPlugin(t *testing.T) {
+	mtracer := mocktracer.New()
+
+	sql.Register("sqltest-gormtrace", &sqltest.Driver{})
+	sqlDB, err := sql.Open("sqltest-gormtrace", "")
+	assert.Nil(t, err)
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}))
+	assert.Nil(t, err)
+	assert.Nil(t, db.Use(NewPlugin(mtracer, WithDBType("mysql"))))
+
+	ctx := context.Background()
+	err = db.WithContext(ctx).Model(&user{ID: 1}).Update("name", "bob").Error
+	assert.Nil(t, err)
+	err = db.WithContext(ctx).First(&user{}, 1).Error
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	err = db.WithContext(ctx).Exec("delete from error where name = 'bob'").Error
+	assert.Equal(t, sqltest.ErrFake, err)
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 3)
+
+	update, query, raw := spans[0], spans[1], spans[2]
+	assert.Equal(t, "gorm.update", update.OperationName)
+	assert.Equal(t, "users", update.Tag("db.table"))
+	assert.Equal(t, int64(1), update.Tag("db.rows_affected"))
+	assert.Equal(t, "mysql", update.Tag("db.type"))
+	assert.Equal(t, "UPDATE `users` SET `name`=? WHERE `id` = ?", update.Tag("db.statement"))
+
+	assert.Equal(t, "gorm.query", query.OperationName)
+	assert.Nil(t, query.Tag("error"))
+
+	assert.Equal(t, "gorm.raw", raw.OperationName)
+	assert.Equal(t, "delete from error where name = ?", raw.Tag("db.statement"))
+	assert.Equal(t, true, raw.Tag("error"))
+}
@@ -0,0 +1,83 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+type goOption struct {
+	detached bool
+}
+
+// GoOption goroutine launcher option
+type GoOption func(*goOption)
+
+// WithGoDetached the span follows from the span of ctx, and the context of fn is not canceled with ctx,
+// for the work which outlives the caller, like async notify.
+func WithGoDetached() GoOption {
+	return func(o *goOption) {
+		o.detached = true
+	}
+}
+
+// Go run fn in a new goroutine with the child span of ctx, the span is finished when fn returns,
+// the error and panic of fn are recorded to the span, the panic is recovered.
+//
+//	tracer.Go(ctx, "send-email", func(ctx context.Context) error {
+//		return sendEmail(ctx, user)
+//	}, tracer.WithGoDetached())
+func Go(ctx context.Context, name string, fn func(ctx context.Context) error, opts ...GoOption) {
+	option := &goOption{}
+	for _, opt := range opts {
+		opt(option)
+	}
+
+	var ref opentracing.StartSpanOption
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		ref = opentracing.ChildOf(parent.Context())
+		if option.detached {
+			ref = opentracing.FollowsFrom(parent.Context())
+		}
+	}
+	if option.detached {
+		ctx = context.WithoutCancel(ctx)
+	}
+
+	var span opentracing.Span
+	if ref != nil {
+		span = opentracing.StartSpan(name, ref)
+	} else {
+		span = opentracing.StartSpan(name)
+	}
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	go runWithSpan(ctx, span, fn)
+}
+
+// runWithSpan run fn, record the error and panic of fn, then finish span.
+func runWithSpan(ctx context.Context, span opentracing.Span, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			ext.Error.Set(span, true)
+			span.LogFields(
+				log.String("event", "panic"),
+				log.String("message", fmt.Sprint(r)),
+				log.String("stack", string(debug.Stack())),
+			)
+		}
+		span.Finish()
+	}()
+
+	err = fn(ctx)
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(log.Error(err))
+	}
+	return err
+}
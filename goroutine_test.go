@@ -0,0 +1,96 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGo(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	parent, ctx := StartSpanFromContext(context.Background(), "parent")
+	ctx, cancel := context.WithCancel(ctx)
+
+	done := make(chan error, 3)
+	Go(ctx, "child", func(ctx context.Context) error {
+		done <- nil
+		return errors.New("failed")
+	})
+	Go(ctx, "panic", func(ctx context.Context) error {
+		defer func() { done <- nil }()
+		panic("boom")
+	})
+	Go(ctx, "detached", func(ctx context.Context) error {
+		<-time.After(10 * time.Millisecond)
+		done <- ctx.Err()
+		return nil
+	}, WithGoDetached())
+	cancel()
+	parent.Finish()
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, <-done)
+	}
+	assert.Eventually(t, func() bool {
+		return len(mtracer.FinishedSpans()) == 4
+	}, time.Second, 10*time.Millisecond)
+
+	spans := make(map[string]*mocktracer.MockSpan)
+	for _, span := range mtracer.FinishedSpans() {
+		spans[span.OperationName] = span
+	}
+
+	parentID := spans["parent"].SpanContext.SpanID
+	assert.Equal(t, parentID, spans["child"].ParentID)
+	assert.Equal(t, true, spans["child"].Tag("error"))
+
+	assert.Equal(t, true, spans["panic"].Tag("error"))
+	assert.Equal(t, "boom", spans["panic"].Logs()[0].Fields[1].ValueString)
+
+	assert.Equal(t, parentID, spans["detached"].ParentID)
+	assert.Nil(t, spans["detached"].Tag("error"))
+}
+
+func TestGoFollowsFrom(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	parent, ctx := StartSpanFromContext(context.Background(), "parent")
+	defer parent.Finish()
+
+	// mocktracer records FollowsFrom as parent, check the reference by a recording tracer.
+	var refs []opentracing.SpanReference
+	SeteTracer(&refTracer{Tracer: mtracer, refs: &refs})
+	defer SeteTracer(mtracer)
+
+	done := make(chan struct{})
+	Go(ctx, "detached", func(ctx context.Context) error {
+		close(done)
+		return nil
+	}, WithGoDetached())
+	<-done
+
+	assert.Len(t, refs, 1)
+	assert.Equal(t, opentracing.FollowsFromRef, refs[0].Type)
+}
+
+type refTracer struct {
+	opentracing.Tracer
+	refs *[]opentracing.SpanReference
+}
+
+func (t *refTracer) StartSpan(name string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	var sso opentracing.StartSpanOptions
+	for _, opt := range opts {
+		opt.Apply(&sso)
+	}
+	*t.refs = append(*t.refs, sso.References...)
+	return t.Tracer.StartSpan(name, opts...)
+}
@@ -0,0 +1,113 @@
+package tracer
+
+import (
+	"context"
+	"sync"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/rfyiamcool/go-tracer/internal/taskgroup"
+)
+
+type groupOption struct {
+	limit int
+}
+
+// GroupOption traced errgroup option
+type GroupOption func(*groupOption)
+
+// WithGroupLimit run at most n tasks concurrently like a worker pool, default: no limit.
+func WithGroupLimit(n int) GroupOption {
+	return func(o *groupOption) {
+		o.limit = n
+	}
+}
+
+// Group is a traced errgroup, each task runs with a child span of the group span,
+// the first error cancels the other tasks and is marked on the group span.
+type Group struct {
+	group *taskgroup.Group
+	span  opentracing.Span
+	name  string
+	limit int
+
+	mu    sync.Mutex
+	tasks int
+	once  sync.Once
+}
+
+// NewGroup start the group span, return the group and the context canceled by the first error.
+//
+//	g, ctx := tracer.NewGroup(ctx, "load-users", tracer.WithGroupLimit(8))
+//	for _, id := range ids {
+//		id := id
+//		g.Go(id, func(ctx context.Context) error {
+//			return loadUser(ctx, id)
+//		})
+//	}
+//	err := g.Wait()
+func NewGroup(ctx context.Context, name string, opts ...GroupOption) (*Group, context.Context) {
+	option := &groupOption{}
+	for _, opt := range opts {
+		opt(option)
+	}
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, name)
+	g := &Group{
+		group: taskgroup.New(ctx, option.limit),
+		span:  span,
+		name:  name,
+		limit: option.limit,
+	}
+	return g, g.group.Context()
+}
+
+// Go run fn in a new goroutine with the task span tagged by index and key, the time waiting
+// for the free worker is tagged as group.task.queue_wait_ms.
+func (g *Group) Go(key string, fn func(ctx context.Context) error) {
+	g.mu.Lock()
+	g.tasks++
+	g.mu.Unlock()
+
+	g.group.Go(key, func(ctx context.Context, task *taskgroup.Task) error {
+		// the span starts when the worker is acquired, the wait is tagged as group.task.queue_wait_ms.
+		span := opentracing.StartSpan(g.name+".task", opentracing.ChildOf(g.span.Context()))
+		span.SetTag("group.task.index", task.Index)
+		if task.Key != "" {
+			span.SetTag("group.task.key", task.Key)
+		}
+		span.SetTag("group.task.queue_wait_ms", task.QueueWait.Milliseconds())
+		if task.Saturated {
+			span.SetTag("group.pool.saturated", true)
+		}
+		if task.Canceled {
+			span.SetTag("group.task.canceled", true)
+			span.Finish()
+			return nil
+		}
+
+		return runWithSpan(opentracing.ContextWithSpan(ctx, span), span, fn)
+	})
+}
+
+// Wait blocks until all tasks return, then finish the group span and return the first error.
+func (g *Group) Wait() error {
+	err := g.group.Wait()
+	g.once.Do(func() {
+		g.mu.Lock()
+		g.span.SetTag("group.tasks", g.tasks)
+		g.mu.Unlock()
+
+		if g.limit > 0 {
+			g.span.SetTag("group.pool.size", g.limit)
+			g.span.SetTag("group.pool.saturated", g.group.Saturated())
+		}
+		if err != nil {
+			ext.Error.Set(g.span, true)
+			g.span.LogFields(log.Error(err))
+		}
+		g.span.Finish()
+	})
+	return err
+}
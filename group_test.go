@@ -0,0 +1,82 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	g, ctx := NewGroup(context.Background(), "load", WithGroupLimit(1))
+
+	started := make(chan struct{})
+	failed := errors.New("failed")
+	g.Go("first", func(ctx context.Context) error {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		return failed
+	})
+	<-started
+	for i := 0; i < 2; i++ {
+		g.Go(strconv.Itoa(i), func(ctx context.Context) error {
+			return nil
+		})
+	}
+
+	assert.Equal(t, failed, g.Wait())
+	assert.NotNil(t, ctx.Err())
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 4)
+
+	group := spans[3]
+	assert.Equal(t, "load", group.OperationName)
+	assert.Equal(t, true, group.Tag("error"))
+	assert.Equal(t, 3, group.Tag("group.tasks"))
+	assert.Equal(t, 1, group.Tag("group.pool.size"))
+	assert.Equal(t, true, group.Tag("group.pool.saturated"))
+
+	for _, span := range spans[:3] {
+		assert.Equal(t, "load.task", span.OperationName)
+		assert.Equal(t, group.SpanContext.SpanID, span.ParentID)
+
+		if span.Tag("group.task.key") == "first" {
+			assert.Equal(t, 0, span.Tag("group.task.index"))
+			assert.Equal(t, true, span.Tag("error"))
+			continue
+		}
+		assert.Equal(t, true, span.Tag("group.task.canceled"))
+		assert.Equal(t, true, span.Tag("group.pool.saturated"))
+		assert.GreaterOrEqual(t, span.Tag("group.task.queue_wait_ms"), int64(10))
+	}
+}
+
+func TestGroupQueueWait(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	g, _ := NewGroup(context.Background(), "load", WithGroupLimit(1))
+	for i := 0; i < 2; i++ {
+		g.Go(strconv.Itoa(i), func(ctx context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+	}
+	assert.Nil(t, g.Wait())
+
+	// the waiting task starts after the running one finishes.
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 3)
+	first, second := spans[0], spans[1]
+	assert.False(t, second.StartTime.Before(first.FinishTime))
+	assert.GreaterOrEqual(t, second.Tag("group.task.queue_wait_ms"), int64(10))
+	assert.Less(t, second.FinishTime.Sub(second.StartTime), 40*time.Millisecond)
+}
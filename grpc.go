@@ -7,8 +7,11 @@ import (
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/log"
+	"github.com/rfyiamcool/go-tracer/internal/grpcutil"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 // MetadataHeader metadata Reader and Writer
@@ -35,13 +38,23 @@ func (c MetadataHeader) Set(key, val string) {
 }
 
 // DialOption grpc client option
-func GrpcDialOption() grpc.DialOption {
-	return grpc.WithUnaryInterceptor(ClientInterceptor())
+func GrpcDialOption(opts ...GrpcOption) grpc.DialOption {
+	return grpc.WithUnaryInterceptor(ClientInterceptor(opts...))
 }
 
 // ServerOption grpc server option
-func GrpcServerOption() grpc.ServerOption {
-	return grpc.UnaryInterceptor(ServerInterceptor())
+func GrpcServerOption(opts ...GrpcOption) grpc.ServerOption {
+	return grpc.UnaryInterceptor(ServerInterceptor(opts...))
+}
+
+// GrpcStreamDialOption grpc stream client option
+func GrpcStreamDialOption(opts ...GrpcOption) grpc.DialOption {
+	return grpc.WithStreamInterceptor(StreamClientInterceptor(opts...))
+}
+
+// GrpcStreamServerOption grpc stream server option
+func GrpcStreamServerOption(opts ...GrpcOption) grpc.ServerOption {
+	return grpc.StreamInterceptor(StreamServerInterceptor(opts...))
 }
 
 // InjectGrpcMD
@@ -70,95 +83,155 @@ func ExtractGrpcHeader(ctx context.Context) (opentracing.SpanContext, error) {
 	return spctx, err
 }
 
+// startGrpcClientSpan start client span and inject span context into outgoing metadata.
+func startGrpcClientSpan(ctx context.Context, method string) (opentracing.Span, context.Context) {
+	var parentCtx opentracing.SpanContext
+	parentSpan := opentracing.SpanFromContext(ctx)
+	if parentSpan != nil {
+		parentCtx = parentSpan.Context()
+	}
+
+	span := gtracer.StartSpan(
+		method,
+		opentracing.ChildOf(parentCtx),
+		opentracing.Tag{Key: string(ext.Component), Value: "gRPC"},
+		ext.SpanKindRPCClient,
+	)
+	setGrpcDeadlineTag(span, ctx)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = metadata.New(nil)
+	} else {
+		md = md.Copy()
+	}
+
+	mdWriter := MetadataHeader{md}
+	err := gtracer.Inject(span.Context(), opentracing.TextMap, mdWriter)
+	if err != nil {
+		span.LogFields(log.String("inject-error", err.Error()))
+	}
+
+	// the attempts of call are recorded by GrpcAttemptHandler.
+	ctx = grpcutil.ContextWithCallState(ContextWithSpan(ctx, span))
+	return span, metadata.NewOutgoingContext(ctx, md)
+}
+
+// startGrpcServerSpan start server span with the span context extracted from incoming metadata.
+func startGrpcServerSpan(ctx context.Context, operation string) opentracing.Span {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		md = metadata.New(nil)
+	}
+
+	var span opentracing.Span
+	spanContext, err := gtracer.Extract(opentracing.TextMap, MetadataHeader{md})
+	if err != nil && err != opentracing.ErrSpanContextNotFound {
+		span = StartSpan(operation)
+	} else {
+		span = gtracer.StartSpan(
+			operation,
+			ext.RPCServerOption(spanContext),
+			opentracing.Tag{Key: string(ext.Component), Value: "gRPC"},
+			ext.SpanKindRPCServer,
+		)
+	}
+
+	p, _ := peer.FromContext(ctx)
+	setGrpcPeerTag(span, p)
+	setGrpcDeadlineTag(span, ctx)
+	return span
+}
+
 // ClientInterceptor grpc client wrapper
-func ClientInterceptor() grpc.UnaryClientInterceptor {
+func ClientInterceptor(fns ...GrpcOption) grpc.UnaryClientInterceptor {
+	option := newGrpcOption(fns)
 	return func(ctx context.Context, method string,
 		req, reply interface{}, cc *grpc.ClientConn,
 		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 
-		var parentCtx opentracing.SpanContext
-		parentSpan := opentracing.SpanFromContext(ctx)
-		if parentSpan != nil {
-			parentCtx = parentSpan.Context()
+		if gtracer == nil || !option.filter.Allow(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
 		}
 
-		span := gtracer.StartSpan(
-			method,
-			opentracing.ChildOf(parentCtx),
-			opentracing.Tag{Key: string(ext.Component), Value: "gRPC"},
-			ext.SpanKindRPCClient,
-		)
+		span, newCtx := startGrpcClientSpan(ctx, method)
 		defer span.Finish()
 
-		md, ok := metadata.FromOutgoingContext(ctx)
-		if !ok {
-			md = metadata.New(nil)
-		} else {
-			md = md.Copy()
-		}
+		span.SetTag("grpc.request.size", grpcMessageSize(req))
+		option.logPayload(span, "grpc.request", req)
 
-		mdWriter := MetadataHeader{md}
-		err := gtracer.Inject(span.Context(), opentracing.TextMap, mdWriter)
-		if err != nil {
-			span.LogFields(log.String("inject-error", err.Error()))
+		var p peer.Peer
+		err := invoker(newCtx, method, req, reply, cc, append(opts[:len(opts):len(opts)], grpc.Peer(&p))...)
+		if err == nil {
+			span.SetTag("grpc.response.size", grpcMessageSize(reply))
+			option.logPayload(span, "grpc.response", reply)
 		}
 
-		newCtx := metadata.NewOutgoingContext(ctx, md)
-		err = invoker(newCtx, method, req, reply, cc, opts...)
-		if err != nil {
-			span.LogFields(log.String("call-error", err.Error()))
-		}
+		setGrpcPeerTag(span, &p)
+		option.setStatus(span, err)
 		return err
 	}
 }
 
 // ServerInterceptor grpc server wrapper
-func ServerInterceptor() grpc.UnaryServerInterceptor {
+func ServerInterceptor(fns ...GrpcOption) grpc.UnaryServerInterceptor {
+	option := newGrpcOption(fns)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		if gtracer == nil {
+		if gtracer == nil || !option.filter.Allow(info.FullMethod) {
 			return handler(ctx, req)
 		}
 
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			md = metadata.New(nil)
-		}
+		span := startGrpcServerSpan(ctx, option.spanName(info))
+		defer span.Finish()
 
-		spanContext, err := gtracer.Extract(opentracing.TextMap, MetadataHeader{md})
-		var span opentracing.Span
-
-		if err != nil && err != opentracing.ErrSpanContextNotFound {
-			span = StartSpan(info.FullMethod)
-		} else {
-			span = gtracer.StartSpan(
-				info.FullMethod,
-				ext.RPCServerOption(spanContext),
-				opentracing.Tag{Key: string(ext.Component), Value: "gRPC"},
-				ext.SpanKindRPCServer,
-			)
-		}
+		span.SetTag("grpc.request.size", grpcMessageSize(req))
+		option.setTags(ctx, span, info, req)
+		option.logPayload(span, "grpc.request", req)
 
-		defer span.Finish()
 		ctx = ContextWithSpan(ctx, span)
 		resp, err = handler(ctx, req)
 		GrpcSendHeader(ctx, nil) // try to send header if not send header.
+		err = option.withErrorDetails(ctx, err)
+
+		if err == nil {
+			span.SetTag("grpc.response.size", grpcMessageSize(resp))
+			option.logPayload(span, "grpc.response", resp)
+		}
+		option.setStatus(span, err)
 		return resp, err
 	}
 }
 
+// GrpcTraceIDFromError get the trace id of server from the status error, the server should enable WithGrpcErrorDetails.
+func GrpcTraceIDFromError(err error) string {
+	if err == nil {
+		return ""
+	}
+	return grpcutil.TraceIDFromStatus(status.Convert(err))
+}
+
 // GrpcSendHeader grpc.SendHeader is called only once.
 func GrpcSendHeader(ctx context.Context, header metadata.MD) {
+	header, ok := grpcTraceHeader(ctx, header)
+	if !ok {
+		return
+	}
+
+	grpc.SendHeader(ctx, header)
+}
+
+// grpcTraceHeader append trace-id, span-id and x-trace-id of context span to header.
+func grpcTraceHeader(ctx context.Context, header metadata.MD) (metadata.MD, bool) {
 	if header == nil {
 		header = metadata.Pairs()
 	}
 	se := GetSpanEntryFromCtx(ctx)
 	if se.IsNull() {
-		return
+		return header, false
 	}
 
 	header.Append(HeaderTraceID, se.TraceID)
 	header.Append(HeaderSpanID, se.SpanID)
 	header.Append(HeaderXTraceID, se.String())
-
-	grpc.SendHeader(ctx, header)
+	return header, true
 }
@@ -0,0 +1,86 @@
+package tracer
+
+import (
+	"context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rfyiamcool/go-tracer/internal/grpcutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+type attemptSpanKey struct{}
+
+// GrpcAttemptHandler grpc client stats.Handler, record each attempt of call as the child span of
+// ClientInterceptor span, so the retries of grpc retry policy are visible.
+type GrpcAttemptHandler struct {
+	option *grpcOption
+}
+
+var _ stats.Handler = &GrpcAttemptHandler{}
+
+// NewGrpcAttemptHandler
+func NewGrpcAttemptHandler(fns ...GrpcOption) *GrpcAttemptHandler {
+	return &GrpcAttemptHandler{
+		option: newGrpcOption(fns),
+	}
+}
+
+// GrpcAttemptDialOption grpc client option, should be used with GrpcDialOption or GrpcStreamDialOption.
+func GrpcAttemptDialOption(fns ...GrpcOption) grpc.DialOption {
+	return grpc.WithStatsHandler(NewGrpcAttemptHandler(fns...))
+}
+
+// TagRPC start attempt span, it's called by grpc before each attempt.
+func (h *GrpcAttemptHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	if gtracer == nil || !h.option.filter.Allow(info.FullMethodName) {
+		return ctx
+	}
+
+	parentSpan := opentracing.SpanFromContext(ctx)
+	if parentSpan == nil {
+		return ctx
+	}
+
+	span := gtracer.StartSpan(
+		info.FullMethodName+"/attempt",
+		opentracing.ChildOf(parentSpan.Context()),
+		opentracing.Tag{Key: string(ext.Component), Value: "gRPC"},
+	)
+	return context.WithValue(ctx, attemptSpanKey{}, span)
+}
+
+// HandleRPC tag the attempt number, backoff and status of attempt.
+func (h *GrpcAttemptHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
+	span, ok := ctx.Value(attemptSpanKey{}).(opentracing.Span)
+	if !ok || !rs.IsClient() {
+		return
+	}
+
+	state := grpcutil.CallStateFromContext(ctx)
+	switch rs := rs.(type) {
+	case *stats.Begin:
+		span.SetTag("grpc.attempt.transparent_retry", rs.IsTransparentRetryAttempt)
+		if state != nil {
+			attempt, backoff := state.Begin(rs.BeginTime)
+			span.SetTag("grpc.attempt", attempt)
+			span.SetTag("grpc.attempt.backoff_ms", backoff.Milliseconds())
+		}
+
+	case *stats.End:
+		if state != nil {
+			state.End(rs.EndTime)
+		}
+		h.option.setStatus(span, rs.Error)
+		span.FinishWithOptions(opentracing.FinishOptions{FinishTime: rs.EndTime})
+	}
+}
+
+// TagConn implements stats.Handler
+func (h *GrpcAttemptHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler
+func (h *GrpcAttemptHandler) HandleConn(ctx context.Context, cs stats.ConnStats) {}
@@ -0,0 +1,171 @@
+package tracer
+
+import (
+	"context"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/rfyiamcool/go-tracer/internal/grpcutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type grpcOption struct {
+	errorCodes map[codes.Code]bool
+	filter     grpcutil.MethodFilter
+
+	errorDetails bool
+
+	payload        bool
+	payloadMaxSize int
+	payloadMasks   []string
+
+	spanNameFunc func(info *grpc.UnaryServerInfo) string
+	tagsFunc     func(ctx context.Context, info *grpc.UnaryServerInfo, req interface{}) map[string]interface{}
+}
+
+func defaultGrpcOption() *grpcOption {
+	return &grpcOption{}
+}
+
+func newGrpcOption(fns []GrpcOption) *grpcOption {
+	option := defaultGrpcOption()
+	for _, fn := range fns {
+		fn(option)
+	}
+	return option
+}
+
+// GrpcOption grpc interceptor option
+type GrpcOption func(*grpcOption)
+
+// WithGrpcErrorCodes only the codes set error tag, default: all non-OK codes.
+func WithGrpcErrorCodes(cs ...codes.Code) GrpcOption {
+	return func(o *grpcOption) {
+		o.errorCodes = make(map[codes.Code]bool, len(cs))
+		for _, code := range cs {
+			o.errorCodes[code] = true
+		}
+	}
+}
+
+// WithGrpcIncludeMethods only trace the methods matched by glob patterns, like /user.User/*
+func WithGrpcIncludeMethods(patterns ...string) GrpcOption {
+	return func(o *grpcOption) {
+		o.filter.Include(patterns...)
+	}
+}
+
+// WithGrpcExcludeMethods skip the methods matched by glob patterns, like /grpc.health.v1.Health/*
+func WithGrpcExcludeMethods(patterns ...string) GrpcOption {
+	return func(o *grpcOption) {
+		o.filter.Exclude(patterns...)
+	}
+}
+
+// WithGrpcErrorDetails attach errdetails.RequestInfo with trace id to the status error returned by server,
+// the client can get trace id by GrpcTraceIDFromError.
+func WithGrpcErrorDetails() GrpcOption {
+	return func(o *grpcOption) {
+		o.errorDetails = true
+	}
+}
+
+// WithGrpcPayload log request and response message by protojson, the fields of masks are masked,
+// like `password`, `user.token`. the payload is truncated to maxSize when maxSize > 0.
+func WithGrpcPayload(maxSize int, masks ...string) GrpcOption {
+	return func(o *grpcOption) {
+		o.payload = true
+		o.payloadMaxSize = maxSize
+		o.payloadMasks = masks
+	}
+}
+
+// WithGrpcSpanName custom the operation name of server span, default: info.FullMethod
+func WithGrpcSpanName(fn func(info *grpc.UnaryServerInfo) string) GrpcOption {
+	return func(o *grpcOption) {
+		o.spanNameFunc = fn
+	}
+}
+
+// WithGrpcTags add custom tags to server span.
+func WithGrpcTags(fn func(ctx context.Context, info *grpc.UnaryServerInfo, req interface{}) map[string]interface{}) GrpcOption {
+	return func(o *grpcOption) {
+		o.tagsFunc = fn
+	}
+}
+
+func (o *grpcOption) spanName(info *grpc.UnaryServerInfo) string {
+	if o.spanNameFunc == nil {
+		return info.FullMethod
+	}
+	return o.spanNameFunc(info)
+}
+
+func (o *grpcOption) setTags(ctx context.Context, span opentracing.Span, info *grpc.UnaryServerInfo, req interface{}) {
+	if o.tagsFunc == nil {
+		return
+	}
+	for key, val := range o.tagsFunc(ctx, info, req) {
+		span.SetTag(key, val)
+	}
+}
+
+func (o *grpcOption) withErrorDetails(ctx context.Context, err error) error {
+	if !o.errorDetails || err == nil {
+		return err
+	}
+
+	traceID, spanID := GetTraceSpanIDsFromCtx(ctx)
+	return grpcutil.WithTraceDetails(err, traceID, spanID)
+}
+
+func (o *grpcOption) logPayload(span opentracing.Span, key string, msg interface{}) {
+	if !o.payload {
+		return
+	}
+	span.LogFields(log.String(key, grpcutil.MarshalPayload(msg, o.payloadMaxSize, o.payloadMasks)))
+}
+
+func (o *grpcOption) isErrorCode(code codes.Code) bool {
+	if code == codes.OK {
+		return false
+	}
+	if o.errorCodes == nil {
+		return true
+	}
+	return o.errorCodes[code]
+}
+
+// setStatus set status code tag, and set error tag by the code of err.
+func (o *grpcOption) setStatus(span opentracing.Span, err error) {
+	code := status.Code(err)
+	span.SetTag("rpc.grpc.status_code", uint32(code))
+	if err == nil {
+		return
+	}
+
+	span.LogFields(log.String("call-error", err.Error()))
+	if o.isErrorCode(code) {
+		ext.Error.Set(span, true)
+	}
+}
+
+func setGrpcPeerTag(span opentracing.Span, p *peer.Peer) {
+	if p == nil || p.Addr == nil {
+		return
+	}
+	ext.PeerAddress.Set(span, p.Addr.String())
+}
+
+func setGrpcDeadlineTag(span opentracing.Span, ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+	span.SetTag("grpc.deadline.remaining_ms", time.Until(deadline).Milliseconds())
+}
@@ -0,0 +1,224 @@
+package tracer
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/rfyiamcool/go-tracer/internal/grpcutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	eventMessageSent     = "message.sent"
+	eventMessageReceived = "message.received"
+)
+
+// StreamClientInterceptor grpc stream client wrapper
+func StreamClientInterceptor(fns ...GrpcOption) grpc.StreamClientInterceptor {
+	option := newGrpcOption(fns)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		if gtracer == nil || !option.filter.Allow(method) {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		span, newCtx := startGrpcClientSpan(ctx, method)
+		setGrpcStreamTags(span, desc)
+
+		var p peer.Peer
+		cs, err := streamer(newCtx, desc, cc, method, append(opts[:len(opts):len(opts)], grpc.Peer(&p))...)
+		if err != nil {
+			option.finishStreamSpan(span, err)
+			return cs, err
+		}
+
+		return newClientStream(newCtx, cs, desc, span, &p, option), nil
+	}
+}
+
+// StreamServerInterceptor grpc stream server wrapper
+func StreamServerInterceptor(fns ...GrpcOption) grpc.StreamServerInterceptor {
+	option := newGrpcOption(fns)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if gtracer == nil || !option.filter.Allow(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		span := startGrpcServerSpan(ss.Context(), info.FullMethod)
+		span.SetTag("grpc.stream.client", info.IsClientStream)
+		span.SetTag("grpc.stream.server", info.IsServerStream)
+
+		ctx := ContextWithSpan(ss.Context(), span)
+		if header, ok := grpcTraceHeader(ctx, nil); ok {
+			ss.SetHeader(header) // send with the first message.
+		}
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx, span: span, option: option})
+		err = option.withErrorDetails(ctx, err)
+		option.finishStreamSpan(span, err)
+		return err
+	}
+}
+
+func setGrpcStreamTags(span opentracing.Span, desc *grpc.StreamDesc) {
+	span.SetTag("grpc.stream.client", desc.ClientStreams)
+	span.SetTag("grpc.stream.server", desc.ServerStreams)
+}
+
+func (o *grpcOption) finishStreamSpan(span opentracing.Span, err error) {
+	switch err {
+	case io.EOF:
+		err = nil
+	case context.Canceled, context.DeadlineExceeded:
+		err = status.FromContextError(err).Err()
+	}
+
+	o.setStatus(span, err)
+	span.Finish()
+}
+
+func (o *grpcOption) logMessage(span opentracing.Span, event string, seq int64, msg interface{}) {
+	fields := []log.Field{
+		log.String("event", event),
+		log.Int64("message.id", seq),
+		log.Int("message.size", grpcMessageSize(msg)),
+	}
+	if o.payload {
+		fields = append(fields, log.String("message.payload", grpcutil.MarshalPayload(msg, o.payloadMaxSize, o.payloadMasks)))
+	}
+	span.LogFields(fields...)
+}
+
+func grpcMessageSize(msg interface{}) int {
+	if pm, ok := msg.(proto.Message); ok {
+		return proto.Size(pm)
+	}
+	return 0
+}
+
+// clientStream finish span when stream is done by EOF, error or context cancel.
+type clientStream struct {
+	grpc.ClientStream
+
+	desc   *grpc.StreamDesc
+	span   opentracing.Span
+	peer   *peer.Peer
+	option *grpcOption
+
+	sentSeq     int64
+	receivedSeq int64
+
+	once sync.Once
+	done chan struct{}
+}
+
+func newClientStream(ctx context.Context, cs grpc.ClientStream, desc *grpc.StreamDesc,
+	span opentracing.Span, p *peer.Peer, option *grpcOption) *clientStream {
+
+	stream := &clientStream{
+		ClientStream: cs,
+		desc:         desc,
+		span:         span,
+		peer:         p,
+		option:       option,
+		done:         make(chan struct{}),
+	}
+
+	if ctx.Done() == nil {
+		return stream // never canceled, the stream is finished by RecvMsg.
+	}
+
+	go func() {
+		select {
+		case <-stream.done:
+		case <-ctx.Done():
+			// peer is filled by grpc in other goroutine when context is done.
+			stream.finish(ctx.Err(), nil)
+		}
+	}()
+	return stream
+}
+
+func (cs *clientStream) finish(err error, p *peer.Peer) {
+	cs.once.Do(func() {
+		close(cs.done)
+		setGrpcPeerTag(cs.span, p)
+		cs.option.finishStreamSpan(cs.span, err)
+	})
+}
+
+func (cs *clientStream) SendMsg(m interface{}) error {
+	err := cs.ClientStream.SendMsg(m)
+	if err == io.EOF {
+		return err // the stream is ended by server, the status is returned by RecvMsg.
+	}
+	if err != nil {
+		cs.finish(err, cs.peer)
+		return err
+	}
+
+	cs.option.logMessage(cs.span, eventMessageSent, atomic.AddInt64(&cs.sentSeq, 1), m)
+	return nil
+}
+
+func (cs *clientStream) RecvMsg(m interface{}) error {
+	err := cs.ClientStream.RecvMsg(m)
+	if err != nil {
+		cs.finish(err, cs.peer)
+		return err
+	}
+
+	cs.option.logMessage(cs.span, eventMessageReceived, atomic.AddInt64(&cs.receivedSeq, 1), m)
+	if !cs.desc.ServerStreams {
+		cs.finish(nil, cs.peer) // only one response message.
+	}
+	return nil
+}
+
+func (cs *clientStream) CloseSend() error {
+	err := cs.ClientStream.CloseSend()
+	if err != nil {
+		cs.finish(err, cs.peer)
+	}
+	return err
+}
+
+// serverStream carry the context with server span.
+type serverStream struct {
+	grpc.ServerStream
+
+	ctx    context.Context
+	span   opentracing.Span
+	option *grpcOption
+
+	sentSeq     int64
+	receivedSeq int64
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+func (ss *serverStream) SendMsg(m interface{}) error {
+	err := ss.ServerStream.SendMsg(m)
+	if err == nil {
+		ss.option.logMessage(ss.span, eventMessageSent, atomic.AddInt64(&ss.sentSeq, 1), m)
+	}
+	return err
+}
+
+func (ss *serverStream) RecvMsg(m interface{}) error {
+	err := ss.ServerStream.RecvMsg(m)
+	if err == nil {
+		ss.option.logMessage(ss.span, eventMessageReceived, atomic.AddInt64(&ss.receivedSeq, 1), m)
+	}
+	return err
+}
@@ -0,0 +1,250 @@
+package tracer
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newHealthConn(t *testing.T, sopts []grpc.ServerOption, dopts ...grpc.DialOption) (*grpc.ClientConn, func()) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(sopts...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}
+	dopts = append(dopts, grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	conn, err := grpc.Dial("bufnet", dopts...)
+	assert.Nil(t, err)
+
+	return conn, func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	conn, stop := newHealthConn(t,
+		[]grpc.ServerOption{GrpcStreamServerOption()},
+		GrpcStreamDialOption(),
+	)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+
+	_, err = stream.Recv()
+	assert.Nil(t, err)
+	cancel()
+
+	_, err = stream.Recv()
+	assert.NotNil(t, err)
+
+	// server span is finished after the handler returns.
+	assert.Eventually(t, func() bool {
+		return len(mtracer.FinishedSpans()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	for _, span := range mtracer.FinishedSpans() {
+		assert.Equal(t, "/grpc.health.v1.Health/Watch", span.OperationName)
+		assert.Equal(t, true, span.Tag("grpc.stream.server"))
+	}
+}
+
+func TestStreamMessageEvents(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	conn, stop := newHealthConn(t,
+		[]grpc.ServerOption{GrpcStreamServerOption()},
+		GrpcStreamDialOption(),
+	)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := &healthpb.HealthCheckRequest{Service: "unknown"}
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, req)
+	assert.Nil(t, err)
+
+	resp, err := stream.Recv()
+	assert.Nil(t, err)
+	cancel()
+
+	assert.Eventually(t, func() bool {
+		return len(mtracer.FinishedSpans()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// the client sends the request first, the server receives it first.
+	reqSize, respSize := strconv.Itoa(proto.Size(req)), strconv.Itoa(proto.Size(resp))
+	for _, span := range mtracer.FinishedSpans() {
+		events, fields := logEvents(span)
+		if span.Tag("span.kind") == ext.SpanKindRPCClientEnum {
+			assert.Equal(t, []string{eventMessageSent, eventMessageReceived}, events)
+			assert.Equal(t, reqSize, fields[eventMessageSent]["message.size"])
+			assert.Equal(t, respSize, fields[eventMessageReceived]["message.size"])
+		} else {
+			assert.Equal(t, []string{eventMessageReceived, eventMessageSent}, events)
+			assert.Equal(t, reqSize, fields[eventMessageReceived]["message.size"])
+			assert.Equal(t, respSize, fields[eventMessageSent]["message.size"])
+		}
+		assert.Equal(t, "1", fields[eventMessageSent]["message.id"])
+		assert.Equal(t, "1", fields[eventMessageReceived]["message.id"])
+	}
+}
+
+type eofClientStream struct {
+	grpc.ClientStream
+}
+
+func (eofClientStream) SendMsg(m interface{}) error {
+	return io.EOF
+}
+
+func (eofClientStream) RecvMsg(m interface{}) error {
+	return status.Error(codes.Unavailable, "closed")
+}
+
+func TestClientStreamSendEOF(t *testing.T) {
+	mtracer := mocktracer.New()
+	span := mtracer.StartSpan("stream")
+
+	desc := &grpc.StreamDesc{ClientStreams: true}
+	cs := newClientStream(context.Background(), eofClientStream{}, desc, span, nil, newGrpcOption(nil))
+
+	// io.EOF of SendMsg doesn't finish the span, the status is returned by RecvMsg.
+	assert.Equal(t, io.EOF, cs.SendMsg(&healthpb.HealthCheckRequest{}))
+	assert.Empty(t, mtracer.FinishedSpans())
+
+	assert.Equal(t, codes.Unavailable, status.Code(cs.RecvMsg(&healthpb.HealthCheckResponse{})))
+	assert.Len(t, mtracer.FinishedSpans(), 1)
+	assert.Equal(t, uint32(codes.Unavailable), mtracer.FinishedSpans()[0].Tag("rpc.grpc.status_code"))
+}
+
+func TestUnaryInterceptorStatus(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	conn, stop := newHealthConn(t,
+		[]grpc.ServerOption{GrpcServerOption()},
+		GrpcDialOption(WithGrpcErrorCodes(codes.Internal)),
+	)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 2)
+
+	server, client := spans[0], spans[1]
+	assert.Equal(t, uint32(codes.NotFound), server.Tag("rpc.grpc.status_code"))
+	assert.Equal(t, true, server.Tag("error"))
+	assert.NotNil(t, server.Tag("grpc.deadline.remaining_ms"))
+
+	assert.Equal(t, uint32(codes.NotFound), client.Tag("rpc.grpc.status_code"))
+	assert.Nil(t, client.Tag("error"))
+	assert.NotEmpty(t, client.Tag("peer.address"))
+}
+
+func TestUnaryInterceptorOption(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	conn, stop := newHealthConn(t,
+		[]grpc.ServerOption{GrpcServerOption(
+			WithGrpcIncludeMethods("/grpc.health.v1.Health/*"),
+			WithGrpcPayload(0, "service"),
+			WithGrpcSpanName(func(info *grpc.UnaryServerInfo) string { return "health" }),
+		)},
+		GrpcDialOption(WithGrpcExcludeMethods("*/Check")),
+	)
+	defer stop()
+
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "secret"})
+	assert.NotNil(t, err)
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "health", spans[0].OperationName)
+
+	payload := spans[0].Logs()[0].Fields[0]
+	assert.Equal(t, "grpc.request", payload.Key)
+	assert.NotContains(t, payload.ValueString, "secret")
+}
+
+func TestGrpcErrorDetails(t *testing.T) {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+	SeteTracer(jtracer)
+
+	conn, stop := newHealthConn(t, []grpc.ServerOption{GrpcServerOption(WithGrpcErrorDetails())})
+	defer stop()
+
+	var header metadata.MD
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"}, grpc.Header(&header))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.NotEmpty(t, GrpcTraceIDFromError(err))
+	assert.Equal(t, header.Get(HeaderTraceID)[0], GrpcTraceIDFromError(err))
+}
+
+func TestGrpcAttemptHandler(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	serviceConfig := `{"methodConfig": [{
+		"name": [{"service": "grpc.health.v1.Health"}],
+		"retryPolicy": {
+			"maxAttempts": 3,
+			"initialBackoff": "0.01s",
+			"maxBackoff": "0.01s",
+			"backoffMultiplier": 1,
+			"retryableStatusCodes": ["NOT_FOUND"]
+		}
+	}]}`
+
+	conn, stop := newHealthConn(t, nil,
+		GrpcDialOption(),
+		GrpcAttemptDialOption(),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	)
+	defer stop()
+
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 4)
+
+	call := spans[3]
+	for i, span := range spans[:3] {
+		assert.Equal(t, "/grpc.health.v1.Health/Check/attempt", span.OperationName)
+		assert.Equal(t, call.SpanContext.SpanID, span.ParentID)
+		assert.Equal(t, i+1, span.Tag("grpc.attempt"))
+		assert.Equal(t, uint32(codes.NotFound), span.Tag("rpc.grpc.status_code"))
+	}
+}
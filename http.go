@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rfyiamcool/go-tracer/internal/httputil"
 )
 
 // InjectHttpHeader
@@ -24,44 +25,55 @@ func ExtractHttpHeader(header http.Header) (opentracing.SpanContext, error) {
 	return spctx, err
 }
 
-// TracingMiddleware gin middleware
-func TracingMiddleware(name string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var (
-			serverSpan    opentracing.Span
-			operationName = fmt.Sprintf("%s:%s", c.Request.URL.Path, c.Request.Method)
+// startHttpServerSpan start server span with the span context extracted from request header.
+func startHttpServerSpan(req *http.Request) opentracing.Span {
+	var (
+		serverSpan    opentracing.Span
+		operationName = fmt.Sprintf("%s:%s", req.URL.Path, req.Method)
+	)
+
+	spctx, err := ExtractHttpHeader(req.Header)
+	if err != nil {
+		serverSpan = gtracer.StartSpan(operationName)
+	} else {
+		serverSpan = opentracing.StartSpan(
+			operationName,
+			ext.RPCServerOption(spctx),
 		)
+	}
+
+	// ext.Component.Set(serverSpan, name)
+	serverSpan.SetTag("http.url", req.URL.Path)
+	serverSpan.SetTag("http.method", req.Method)
+	serverSpan.SetTag("http.headers.xff", req.Header.Get("X-Forwarded-For"))
+	serverSpan.SetTag("http.headers.ua", req.Header.Get("User-Agent"))
+	serverSpan.SetTag("http.request.time", time.Now().Format(time.RFC3339))
+	serverSpan.SetTag("http.headers", marshal(req.Header))
+
+	// body, err := ioutil.ReadAll(req.Body)
+	// if err == nil {
+	// 	opentracing.Tag{Key: "http.request.body", Value: string(body)}.Set(serverSpan)
+	// }
+
+	return serverSpan
+}
 
-		spctx, err := ExtractHttpHeader(c.Request.Header)
-		if err != nil {
-			serverSpan = gtracer.StartSpan(operationName)
-		} else {
-			serverSpan = opentracing.StartSpan(
-				operationName,
-				ext.RPCServerOption(spctx),
-			)
-		}
+// setHttpRespHeader write trace-id and span-id to resp header
+func setHttpRespHeader(span opentracing.Span, header http.Header) {
+	traceID, spanID := GetTraceSpanIDs(span)
+	header.Set(HeaderTraceID, traceID)
+	header.Set(HeaderSpanID, spanID)
+}
 
+// TracingMiddleware gin middleware
+func TracingMiddleware(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serverSpan := startHttpServerSpan(c.Request)
 		defer serverSpan.Finish()
 
 		c.Set("root_span_ctx", serverSpan.Context())
 
-		// ext.Component.Set(serverSpan, name)
-		serverSpan.SetTag("http.url", c.Request.URL.Path)
-		serverSpan.SetTag("http.method", c.Request.Method)
-		serverSpan.SetTag("http.headers.xff", c.Request.Header.Get("X-Forwarded-For"))
-		serverSpan.SetTag("http.headers.ua", c.Request.Header.Get("User-Agent"))
-		serverSpan.SetTag("http.request.time", time.Now().Format(time.RFC3339))
-		serverSpan.SetTag("http.headers", marshal(c.Request.Header))
-
-		// body, err := ioutil.ReadAll(c.Request.Body)
-		// if err == nil {
-		// 	opentracing.Tag{Key: "http.request.body", Value: string(body)}.Set(serverSpan)
-		// }
-
-		traceID, spanID := GetTraceSpanIDs(serverSpan)
-		c.Writer.Header().Set(HeaderTraceID, traceID)
-		c.Writer.Header().Set(HeaderSpanID, spanID)
+		setHttpRespHeader(serverSpan, c.Writer.Header())
 		c.Request = c.Request.WithContext(opentracing.ContextWithSpan(c.Request.Context(), serverSpan))
 
 		c.Next()
@@ -70,3 +82,21 @@ func TracingMiddleware(name string) gin.HandlerFunc {
 		serverSpan.SetTag("http.request.errors", c.Errors.String())
 	}
 }
+
+// HttpMiddleware net/http middleware, usage: http.ListenAndServe(addr, tracer.HttpMiddleware(name)(mux))
+func HttpMiddleware(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serverSpan := startHttpServerSpan(r)
+			defer serverSpan.Finish()
+
+			setHttpRespHeader(serverSpan, w.Header())
+			r = r.WithContext(opentracing.ContextWithSpan(r.Context(), serverSpan))
+
+			rw := httputil.NewResponseWriter(w)
+			next.ServeHTTP(rw.Wrap(), r)
+
+			ext.HTTPStatusCode.Set(serverSpan, uint16(rw.Status()))
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package tracer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/spf13/cast"
+)
+
+// Transport http.RoundTripper which create client span for each request.
+type Transport struct {
+	base http.RoundTripper
+
+	clientTrace bool
+}
+
+var _ http.RoundTripper = &Transport{}
+
+type TransportOption func(*Transport)
+
+// WithClientTrace log the connection phase events of request on the client span, such as dns, connect and tls handshake.
+func WithClientTrace() TransportOption {
+	return func(t *Transport) {
+		t.clientTrace = true
+	}
+}
+
+// NewTransport wrap base RoundTripper, use http.DefaultTransport when base is nil.
+func NewTransport(base http.RoundTripper, opts ...TransportOption) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t := &Transport{
+		base: base,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WrapHttpClient return a copy of client with traced transport, use http.DefaultClient when client is nil.
+func WrapHttpClient(client *http.Client, opts ...TransportOption) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	cli := *client
+	cli.Transport = NewTransport(client.Transport, opts...)
+	return &cli
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if gtracer == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	var parentCtx opentracing.SpanContext
+	parentSpan := opentracing.SpanFromContext(req.Context())
+	if parentSpan != nil {
+		parentCtx = parentSpan.Context()
+	}
+
+	span := gtracer.StartSpan(
+		fmt.Sprintf("%s:%s", req.URL.Path, req.Method),
+		opentracing.ChildOf(parentCtx),
+		opentracing.Tag{Key: string(ext.Component), Value: "net/http"},
+		ext.SpanKindRPCClient,
+	)
+	defer span.Finish()
+
+	ext.HTTPMethod.Set(span, req.Method)
+	ext.HTTPUrl.Set(span, req.URL.String())
+	ext.PeerHostname.Set(span, req.URL.Hostname())
+	if port := req.URL.Port(); port != "" {
+		ext.PeerPort.Set(span, cast.ToUint16(port))
+	}
+
+	ctx := opentracing.ContextWithSpan(req.Context(), span)
+	if t.clientTrace {
+		ctx = httptrace.WithClientTrace(ctx, newClientTrace(span))
+	}
+
+	// RoundTripper should not modify the origin request.
+	req = req.Clone(ctx)
+	err := InjectHttpHeader(span, req.Header)
+	if err != nil {
+		span.LogFields(log.String("inject-error", err.Error()))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(log.String("call-error", err.Error()))
+		return resp, err
+	}
+
+	ext.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		ext.Error.Set(span, true)
+	}
+	return resp, err
+}
+
+// newClientTrace log the connection phase events to span.
+func newClientTrace(span opentracing.Span) *httptrace.ClientTrace {
+	logEvent := func(event string, err error, fields ...log.Field) {
+		fields = append([]log.Field{log.String("event", event)}, fields...)
+		if err != nil {
+			fields = append(fields, log.Error(err))
+		}
+		span.LogFields(fields...)
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			logEvent("dns.start", nil, log.String("host", info.Host))
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			addrs := make([]string, 0, len(info.Addrs))
+			for _, addr := range info.Addrs {
+				addrs = append(addrs, addr.String())
+			}
+			logEvent("dns.done", info.Err, log.String("addrs", strings.Join(addrs, ",")))
+		},
+		ConnectStart: func(network, addr string) {
+			logEvent("connect.start", nil, log.String("network", network), log.String("addr", addr))
+		},
+		ConnectDone: func(network, addr string, err error) {
+			logEvent("connect.done", err, log.String("network", network), log.String("addr", addr))
+		},
+		TLSHandshakeStart: func() {
+			logEvent("tls.start", nil)
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			logEvent("tls.done", err, log.Bool("resumed", state.DidResume), log.String("server_name", state.ServerName))
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			logEvent("got_conn", nil,
+				log.Bool("reused", info.Reused),
+				log.Bool("was_idle", info.WasIdle),
+				log.String("idle_time", info.IdleTime.String()),
+			)
+		},
+		GotFirstResponseByte: func() {
+			logEvent("first_response_byte", nil)
+		},
+	}
+}
@@ -0,0 +1,131 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpMiddleware(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	handler := HttpMiddleware("test")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotNil(t, SpanFromContext(r.Context()))
+
+		_, ok := w.(http.Flusher)
+		assert.True(t, ok)
+		_, ok = w.(http.Hijacker)
+		assert.False(t, ok)
+
+		w.WriteHeader(http.StatusNotFound)
+		w.(http.Flusher).Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "/ping:GET", spans[0].OperationName)
+	assert.Equal(t, uint16(http.StatusNotFound), spans[0].Tag("http.status_code"))
+}
+
+func TestTransport(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("mockpfx-ids-traceid"))
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := WrapHttpClient(nil)
+	resp, err := client.Get(server.URL + "/ping")
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, uint16(http.StatusBadGateway), spans[0].Tag("http.status_code"))
+	assert.Equal(t, true, spans[0].Tag("error"))
+}
+
+// logEvents returns the events of span logs, and the fields of each event, the logs without
+// event are skipped.
+func logEvents(span *mocktracer.MockSpan) ([]string, map[string]map[string]string) {
+	var events []string
+	fields := make(map[string]map[string]string)
+	for _, record := range span.Logs() {
+		kv := make(map[string]string)
+		for _, f := range record.Fields {
+			kv[f.Key] = f.ValueString
+		}
+		if event, ok := kv["event"]; ok {
+			events = append(events, event)
+			fields[event] = kv
+		}
+	}
+	return events, fields
+}
+
+func TestTransportClientTrace(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// resolve localhost to trace the dns phase.
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/ping"
+	client := WrapHttpClient(&http.Client{Transport: &http.Transport{}}, WithClientTrace())
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(url)
+		assert.Nil(t, err)
+		resp.Body.Close()
+	}
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 2)
+
+	events, fields := logEvents(spans[0])
+	assert.Equal(t, []string{"dns.start", "dns.done", "connect.start", "connect.done", "got_conn", "first_response_byte"}, events)
+	assert.Equal(t, "localhost", fields["dns.start"]["host"])
+	assert.Contains(t, fields["dns.done"]["addrs"], "127.0.0.1")
+	assert.Equal(t, "tcp", fields["connect.start"]["network"])
+	assert.Equal(t, server.Listener.Addr().String(), fields["connect.done"]["addr"])
+	assert.Empty(t, fields["connect.done"]["error.object"])
+	assert.Equal(t, "false", fields["got_conn"]["reused"])
+
+	// the idle connection is reused without dns and connect.
+	events, fields = logEvents(spans[1])
+	assert.Equal(t, []string{"got_conn", "first_response_byte"}, events)
+	assert.Equal(t, "true", fields["got_conn"]["reused"])
+	assert.Equal(t, "true", fields["got_conn"]["was_idle"])
+}
+
+func TestTransportClientTraceError(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := WrapHttpClient(&http.Client{Transport: &http.Transport{}}, WithClientTrace())
+	_, err := client.Get(server.URL + "/ping")
+	assert.NotNil(t, err)
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, true, spans[0].Tag("error"))
+
+	events, fields := logEvents(spans[0])
+	assert.Equal(t, []string{"connect.start", "connect.done"}, events)
+	assert.Contains(t, fields["connect.done"]["error.object"], "connection refused")
+}
@@ -0,0 +1,48 @@
+package grpcutil
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type callStateKey struct{}
+
+// CallState record the attempts of a logical grpc call, the attempts are sequential in grpc-go,
+// hedging attempts are concurrent when grpc-go supports it.
+type CallState struct {
+	mu       sync.Mutex
+	attempts int
+	lastEnd  time.Time
+}
+
+// ContextWithCallState return a new context that holds a CallState.
+func ContextWithCallState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callStateKey{}, &CallState{})
+}
+
+// CallStateFromContext return the CallState in the context, return nil if not found.
+func CallStateFromContext(ctx context.Context) *CallState {
+	state, _ := ctx.Value(callStateKey{}).(*CallState)
+	return state
+}
+
+// Begin return the attempt number starting from 1, and the backoff since previous attempt is done.
+func (s *CallState) Begin(now time.Time) (int, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+	if s.lastEnd.IsZero() {
+		return s.attempts, 0
+	}
+	return s.attempts, now.Sub(s.lastEnd)
+}
+
+// End record the end time of attempt.
+func (s *CallState) End(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastEnd = now
+}
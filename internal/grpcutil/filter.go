@@ -0,0 +1,76 @@
+package grpcutil
+
+// MethodFilter decide which grpc full method should be traced by glob patterns,
+// the `*` matches any sequence of characters including `/`, the `?` matches any single character.
+//
+// example: /grpc.health.v1.Health/*, /grpc.reflection.*
+type MethodFilter struct {
+	includes []string
+	excludes []string
+}
+
+// Include only trace the matched methods, default: all methods.
+func (f *MethodFilter) Include(patterns ...string) {
+	f.includes = append(f.includes, patterns...)
+}
+
+// Exclude skip the matched methods, exclude takes precedence over include.
+func (f *MethodFilter) Exclude(patterns ...string) {
+	f.excludes = append(f.excludes, patterns...)
+}
+
+// Allow return true if the method should be traced.
+func (f *MethodFilter) Allow(method string) bool {
+	for _, pattern := range f.excludes {
+		if Glob(pattern, method) {
+			return false
+		}
+	}
+	if len(f.includes) == 0 {
+		return true
+	}
+	for _, pattern := range f.includes {
+		if Glob(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// Glob reports whether the str matches the pattern.
+func Glob(pattern, str string) bool {
+	var (
+		px, sx         int
+		nextPx, nextSx = -1, -1
+	)
+
+	for px < len(pattern) || sx < len(str) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				// try to match at sx, restart at sx+1 if failed.
+				nextPx, nextSx = px, sx+1
+				px++
+				continue
+			case '?':
+				if sx < len(str) {
+					px++
+					sx++
+					continue
+				}
+			default:
+				if sx < len(str) && str[sx] == c {
+					px++
+					sx++
+					continue
+				}
+			}
+		}
+		if nextSx > 0 && nextSx <= len(str) {
+			px, sx = nextPx, nextSx
+			continue
+		}
+		return false
+	}
+	return true
+}
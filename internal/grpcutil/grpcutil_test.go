@@ -0,0 +1,54 @@
+package grpcutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestGlob(t *testing.T) {
+	assert.True(t, Glob("/grpc.health.v1.Health/*", "/grpc.health.v1.Health/Check"))
+	assert.True(t, Glob("/grpc.reflection.*", "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"))
+	assert.True(t, Glob("*/Check", "/grpc.health.v1.Health/Check"))
+	assert.True(t, Glob("/user.User/Get?", "/user.User/Get1"))
+	assert.False(t, Glob("/user.User/Get?", "/user.User/Get"))
+	assert.False(t, Glob("/grpc.health.v1.Health/*", "/user.User/Get"))
+
+	var filter MethodFilter
+	filter.Include("/user.*")
+	filter.Exclude("*/Ping")
+	assert.True(t, filter.Allow("/user.User/Get"))
+	assert.False(t, filter.Allow("/user.User/Ping"))
+	assert.False(t, filter.Allow("/order.Order/Get"))
+}
+
+func TestMarshalPayload(t *testing.T) {
+	req := &healthpb.HealthCheckRequest{Service: "secret"}
+
+	out := MarshalPayload(req, 0, []string{"service"})
+	assert.Contains(t, out, `"******"`)
+	assert.NotContains(t, out, "secret")
+	assert.Equal(t, "secret", req.Service)
+
+	out = MarshalPayload(req, 10, nil)
+	assert.Equal(t, `{"service"...(truncated)`, out)
+	assert.Equal(t, `{"k":"v"}`, MarshalPayload(map[string]string{"k": "v"}, 0, nil))
+}
+
+func TestTraceDetails(t *testing.T) {
+	err := WithTraceDetails(status.Error(codes.Internal, "db failed"), "trace-1", "span-1")
+	st := status.Convert(err)
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, "db failed", st.Message())
+	assert.Equal(t, "trace-1", TraceIDFromStatus(st))
+
+	// keep the trace id of the first server.
+	err = WithTraceDetails(err, "trace-2", "span-2")
+	assert.Equal(t, "trace-1", TraceIDFromStatus(status.Convert(err)))
+
+	assert.Nil(t, WithTraceDetails(nil, "trace-1", "span-1"))
+	assert.Equal(t, "", TraceIDFromStatus(status.New(codes.Internal, "")))
+}
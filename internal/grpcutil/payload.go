@@ -0,0 +1,74 @@
+package grpcutil
+
+import (
+	"encoding/json"
+	"strings"
+
+	protov1 "github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const maskedValue = "******"
+
+// MarshalPayload serialize message by protojson, the fields of masks are masked, nested field is separated by dot,
+// like `password`, `user.token`. the result is truncated to maxSize when maxSize > 0.
+func MarshalPayload(msg interface{}, maxSize int, masks []string) string {
+	var out string
+
+	if pm, ok := msg.(protov1.Message); ok {
+		m := protov1.MessageV2(pm)
+		if len(masks) > 0 {
+			m = proto.Clone(m)
+			for _, mask := range masks {
+				maskField(m.ProtoReflect(), strings.Split(mask, "."))
+			}
+		}
+
+		bs, err := protojson.Marshal(m)
+		if err != nil {
+			out = err.Error()
+		} else {
+			out = string(bs)
+		}
+	} else {
+		bs, err := json.Marshal(msg)
+		if err != nil {
+			out = err.Error()
+		} else {
+			out = string(bs)
+		}
+	}
+
+	return Truncate(out, maxSize)
+}
+
+// Truncate cut str to maxSize bytes when maxSize > 0.
+func Truncate(str string, maxSize int) string {
+	if maxSize <= 0 || len(str) <= maxSize {
+		return str
+	}
+	return str[:maxSize] + "...(truncated)"
+}
+
+func maskField(msg protoreflect.Message, path []string) {
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if fd == nil || !msg.Has(fd) {
+		return
+	}
+
+	if len(path) > 1 {
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return
+		}
+		maskField(msg.Mutable(fd).Message(), path[1:])
+		return
+	}
+
+	if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() {
+		msg.Set(fd, protoreflect.ValueOfString(maskedValue))
+		return
+	}
+	msg.Clear(fd)
+}
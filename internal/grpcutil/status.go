@@ -0,0 +1,39 @@
+package grpcutil
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WithTraceDetails attach errdetails.RequestInfo with trace id to the status of err,
+// the RequestId is trace id, the ServingData is span id.
+func WithTraceDetails(err error, traceID, spanID string) error {
+	if err == nil || traceID == "" {
+		return err
+	}
+
+	st := status.Convert(err)
+	if st.Code() == codes.OK || TraceIDFromStatus(st) != "" {
+		return err
+	}
+
+	dst, derr := st.WithDetails(&errdetails.RequestInfo{
+		RequestId:   traceID,
+		ServingData: spanID,
+	})
+	if derr != nil {
+		return err
+	}
+	return dst.Err()
+}
+
+// TraceIDFromStatus get trace id from the errdetails.RequestInfo of status.
+func TraceIDFromStatus(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RequestInfo); ok && info.RequestId != "" {
+			return info.RequestId
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+// Package httputil record the status code written by the http handler.
+package httputil
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter record the status code written by handler.
+type ResponseWriter struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool
+}
+
+// NewResponseWriter wrap w, the status is 200 if the handler doesn't write header.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+	}
+}
+
+// Status returns the status code written by handler.
+func (rw *ResponseWriter) Status() int {
+	return rw.status
+}
+
+func (rw *ResponseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.status = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *ResponseWriter) Write(bs []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(bs)
+}
+
+// Unwrap used by http.ResponseController
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Wrap only expose the optional interfaces which are implemented by the origin writer,
+// so the type assertion of handler is the same as without middleware.
+func (rw *ResponseWriter) Wrap() http.ResponseWriter {
+	var (
+		flusher, isFlusher   = rw.ResponseWriter.(http.Flusher)
+		hijacker, isHijacker = rw.ResponseWriter.(http.Hijacker)
+		pusher, isPusher     = rw.ResponseWriter.(http.Pusher)
+	)
+
+	if isFlusher {
+		flusher = flushFunc(func() {
+			rw.wroteHeader = true
+			rw.ResponseWriter.(http.Flusher).Flush()
+		})
+	}
+	if isHijacker {
+		hijacker = hijackFunc(func() (net.Conn, *bufio.ReadWriter, error) {
+			conn, brw, err := rw.ResponseWriter.(http.Hijacker).Hijack()
+			if err == nil {
+				rw.status = http.StatusSwitchingProtocols
+				rw.wroteHeader = true
+			}
+			return conn, brw, err
+		})
+	}
+
+	switch {
+	case isFlusher && isHijacker && isPusher:
+		return struct {
+			*ResponseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{rw, flusher, hijacker, pusher}
+	case isFlusher && isHijacker:
+		return struct {
+			*ResponseWriter
+			http.Flusher
+			http.Hijacker
+		}{rw, flusher, hijacker}
+	case isFlusher && isPusher:
+		return struct {
+			*ResponseWriter
+			http.Flusher
+			http.Pusher
+		}{rw, flusher, pusher}
+	case isHijacker && isPusher:
+		return struct {
+			*ResponseWriter
+			http.Hijacker
+			http.Pusher
+		}{rw, hijacker, pusher}
+	case isFlusher:
+		return struct {
+			*ResponseWriter
+			http.Flusher
+		}{rw, flusher}
+	case isHijacker:
+		return struct {
+			*ResponseWriter
+			http.Hijacker
+		}{rw, hijacker}
+	case isPusher:
+		return struct {
+			*ResponseWriter
+			http.Pusher
+		}{rw, pusher}
+	}
+	return rw
+}
+
+type flushFunc func()
+
+func (fn flushFunc) Flush() { fn() }
+
+type hijackFunc func() (net.Conn, *bufio.ReadWriter, error)
+
+func (fn hijackFunc) Hijack() (net.Conn, *bufio.ReadWriter, error) { return fn() }
@@ -0,0 +1,48 @@
+package httputil
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+func TestResponseWriter(t *testing.T) {
+	rw := NewResponseWriter(httptest.NewRecorder())
+	w := rw.Wrap()
+	_, ok := w.(http.Flusher)
+	assert.True(t, ok)
+	_, ok = w.(http.Hijacker)
+	assert.False(t, ok)
+
+	w.Write([]byte("ok"))
+	w.WriteHeader(http.StatusNotFound)
+	assert.Equal(t, http.StatusOK, rw.Status())
+
+	rw = NewResponseWriter(httptest.NewRecorder())
+	rw.Wrap().WriteHeader(http.StatusBadGateway)
+	assert.Equal(t, http.StatusBadGateway, rw.Status())
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	rw := NewResponseWriter(hijackRecorder{httptest.NewRecorder()})
+	w := rw.Wrap()
+	_, ok := w.(http.Flusher)
+	assert.True(t, ok)
+
+	_, _, err := w.(http.Hijacker).Hijack()
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, rw.Status())
+	assert.Equal(t, w.(interface{ Unwrap() http.ResponseWriter }).Unwrap(), rw.ResponseWriter)
+}
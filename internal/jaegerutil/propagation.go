@@ -0,0 +1,55 @@
+package jaegerutil
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+// HeaderXTraceID the header of jaeger span context, like `{trace-id}:{span-id}:{parent-id}:{flags}`.
+const HeaderXTraceID = "x-trace-id"
+
+var errStopForeach = errors.New("stop foreach")
+
+// Inject inject the span context by the tracer of span, x-trace-id and traceparent.
+func Inject(span opentracing.Span, carrier opentracing.TextMapWriter) error {
+	if err := span.Tracer().Inject(span.Context(), opentracing.TextMap, carrier); err != nil {
+		return err
+	}
+
+	if sc, ok := span.Context().(jaeger.SpanContext); ok {
+		carrier.Set(HeaderXTraceID, sc.String())
+		carrier.Set(HeaderTraceparent, Traceparent(sc))
+	}
+	return nil
+}
+
+// Extract extract the span context by tracer, then x-trace-id and traceparent.
+func Extract(tracer opentracing.Tracer, carrier opentracing.TextMapReader) (opentracing.SpanContext, error) {
+	spctx, err := tracer.Extract(opentracing.TextMap, carrier)
+	if err == nil {
+		return spctx, nil
+	}
+
+	if sc, err := jaeger.ContextFromString(get(carrier, HeaderXTraceID)); err == nil {
+		return sc, nil
+	}
+	if sc, ok := ParseTraceparent(get(carrier, HeaderTraceparent)); ok {
+		return sc, nil
+	}
+	return nil, err
+}
+
+func get(carrier opentracing.TextMapReader, key string) string {
+	var val string
+	carrier.ForeachKey(func(k, v string) error {
+		if strings.EqualFold(k, key) {
+			val = v
+			return errStopForeach
+		}
+		return nil
+	})
+	return val
+}
@@ -0,0 +1,45 @@
+// Package jaegerutil convert the jaeger span context to w3c trace context.
+package jaegerutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/uber/jaeger-client-go"
+)
+
+// HeaderTraceparent the header of w3c trace context.
+const HeaderTraceparent = "traceparent"
+
+// Traceparent format the span context by w3c trace context, like `00-{trace-id}-{span-id}-01`.
+func Traceparent(sc jaeger.SpanContext) string {
+	if !sc.IsValid() {
+		return ""
+	}
+
+	flags := "00"
+	if sc.IsSampled() {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%016x%016x-%016x-%s", sc.TraceID().High, sc.TraceID().Low, uint64(sc.SpanID()), flags)
+}
+
+// ParseTraceparent parse the span context from w3c traceparent.
+func ParseTraceparent(s string) (jaeger.SpanContext, bool) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return jaeger.SpanContext{}, false
+	}
+
+	high, err1 := strconv.ParseUint(parts[1][:16], 16, 64)
+	low, err2 := strconv.ParseUint(parts[1][16:], 16, 64)
+	spanID, err3 := strconv.ParseUint(parts[2], 16, 64)
+	flags, err4 := strconv.ParseUint(parts[3], 16, 8)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return jaeger.SpanContext{}, false
+	}
+
+	sc := jaeger.NewSpanContext(jaeger.TraceID{High: high, Low: low}, jaeger.SpanID(spanID), 0, flags&1 == 1, nil)
+	return sc, sc.IsValid()
+}
@@ -0,0 +1,24 @@
+package jaegerutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestTraceparent(t *testing.T) {
+	sc := jaeger.NewSpanContext(jaeger.TraceID{High: 1, Low: 2}, jaeger.SpanID(3), 0, true, nil)
+
+	s := Traceparent(sc)
+	assert.Equal(t, "00-00000000000000010000000000000002-0000000000000003-01", s)
+
+	parsed, ok := ParseTraceparent(s)
+	assert.True(t, ok)
+	assert.Equal(t, sc.TraceID(), parsed.TraceID())
+	assert.Equal(t, sc.SpanID(), parsed.SpanID())
+	assert.True(t, parsed.IsSampled())
+
+	_, ok = ParseTraceparent("00-invalid")
+	assert.False(t, ok)
+}
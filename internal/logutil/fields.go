@@ -0,0 +1,30 @@
+// Package logutil the shared fields of log integrations, zap and logrus.
+package logutil
+
+import (
+	"sort"
+)
+
+const (
+	// TraceIDKey the key of trace id in log entry.
+	TraceIDKey = "trace_id"
+	// SpanIDKey the key of span id in log entry.
+	SpanIDKey = "span_id"
+
+	// EventLog the name of span log or event mirrored from log entry.
+	EventLog = "log"
+	// LevelKey the key of level in span log or event.
+	LevelKey = "log.severity"
+	// MessageKey the key of message in span log or event.
+	MessageKey = "log.message"
+)
+
+// SortedKeys returns the sorted keys of fields, span logs are stable in order.
+func SortedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
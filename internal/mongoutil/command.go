@@ -0,0 +1,148 @@
+// Package mongoutil format the command of mongo for span.
+package mongoutil
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// the fields added by driver, not the part of command.
+var skipFields = map[string]bool{
+	"lsid":             true,
+	"$clusterTime":     true,
+	"$db":              true,
+	"$readPreference":  true,
+	"txnNumber":        true,
+	"autocommit":       true,
+	"startTransaction": true,
+}
+
+// Collection return the collection of command, like `users` of `{"find": "users"}`.
+func Collection(command bson.Raw) string {
+	elem, err := command.IndexErr(0)
+	if err != nil {
+		return ""
+	}
+	if s, ok := elem.Value().StringValueOK(); ok {
+		return s
+	}
+	return ""
+}
+
+// Statement return the sanitized command, the values are replaced with `?` except the collection,
+// like `{"find":"users","filter":{"name":"?"}}`.
+func Statement(command bson.Raw) string {
+	elems, err := command.Elements()
+	if err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	n := 0
+	for i, elem := range elems {
+		key := elem.Key()
+		if skipFields[key] {
+			continue
+		}
+		if n > 0 {
+			b.WriteByte(',')
+		}
+		n++
+
+		b.WriteString(strconv.Quote(key))
+		b.WriteByte(':')
+		if i == 0 && elem.Value().Type == bsontype.String {
+			b.WriteString(strconv.Quote(elem.Value().StringValue()))
+			continue
+		}
+		writeValue(&b, elem.Value())
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func writeValue(b *strings.Builder, val bson.RawValue) {
+	switch val.Type {
+	case bsontype.EmbeddedDocument:
+		elems, _ := val.Document().Elements()
+		b.WriteByte('{')
+		for i, elem := range elems {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Quote(elem.Key()))
+			b.WriteByte(':')
+			writeValue(b, elem.Value())
+		}
+		b.WriteByte('}')
+
+	case bsontype.Array:
+		values, _ := val.Array().Values()
+		b.WriteByte('[')
+		for i, v := range values {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeValue(b, v)
+		}
+		b.WriteByte(']')
+
+	default:
+		b.WriteString(`"?"`)
+	}
+}
+
+// PeerHostPort parse the host and port of the connection id, like `localhost:27017[-5]`.
+func PeerHostPort(connectionID string) (string, int) {
+	addr := connectionID
+	if idx := strings.IndexByte(addr, '['); idx >= 0 {
+		addr = addr[:idx]
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, 0
+	}
+	n, _ := strconv.Atoi(port)
+	return host, n
+}
+
+// SpanMap store the span of command by connection id and request id,
+// the span is started by Started and finished by Succeeded or Failed.
+type SpanMap struct {
+	mu    sync.Mutex
+	spans map[spanKey]interface{}
+}
+
+type spanKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// Store the span of the command.
+func (m *SpanMap) Store(connectionID string, requestID int64, span interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.spans == nil {
+		m.spans = make(map[spanKey]interface{})
+	}
+	m.spans[spanKey{connectionID, requestID}] = span
+}
+
+// LoadAndDelete return the span of the command and remove it.
+func (m *SpanMap) LoadAndDelete(connectionID string, requestID int64) (interface{}, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := spanKey{connectionID, requestID}
+	span, ok := m.spans[key]
+	delete(m.spans, key)
+	return span, ok
+}
@@ -0,0 +1,27 @@
+package mongoutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestStatement(t *testing.T) {
+	command, err := bson.Marshal(bson.D{
+		{Key: "find", Value: "users"},
+		{Key: "filter", Value: bson.D{{Key: "name", Value: "bob"}, {Key: "age", Value: bson.D{{Key: "$in", Value: bson.A{1, 2}}}}}},
+		{Key: "limit", Value: 1},
+		{Key: "$db", Value: "test"},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, "users", Collection(command))
+	assert.Equal(t, `{"find":"users","filter":{"name":"?","age":{"$in":["?","?"]}},"limit":"?"}`, Statement(command))
+}
+
+func TestPeerHostPort(t *testing.T) {
+	host, port := PeerHostPort("localhost:27017[-5]")
+	assert.Equal(t, "localhost", host)
+	assert.Equal(t, 27017, port)
+}
@@ -0,0 +1,91 @@
+// Package otelutil propagate the opentelemetry span context by the x-trace-id of jaeger format.
+package otelutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderXTraceID the header of jaeger span context, like `{trace-id}:{span-id}:{parent-id}:{flags}`.
+const HeaderXTraceID = "x-trace-id"
+
+// XTraceIDPropagator propagate the span context by x-trace-id, the span context extracted by
+// the previous propagator is not replaced.
+type XTraceIDPropagator struct{}
+
+var _ propagation.TextMapPropagator = XTraceIDPropagator{}
+
+// Inject set x-trace-id by the span context of ctx.
+func (XTraceIDPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	carrier.Set(HeaderXTraceID, XTraceID(sc))
+}
+
+// Extract return the context with the remote span context of x-trace-id.
+func (XTraceIDPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	sc, ok := ParseXTraceID(carrier.Get(HeaderXTraceID))
+	if !ok {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields return the keys of carrier.
+func (XTraceIDPropagator) Fields() []string {
+	return []string{HeaderXTraceID}
+}
+
+// Propagator return the propagator of w3c trace context, baggage and x-trace-id.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+		XTraceIDPropagator{},
+	)
+}
+
+// XTraceID format the span context by jaeger, like `{trace-id}:{span-id}:0:{flags}`.
+func XTraceID(sc trace.SpanContext) string {
+	return fmt.Sprintf("%s:%s:0:%s", sc.TraceID(), sc.SpanID(), sc.TraceFlags())
+}
+
+// ParseXTraceID parse the span context from x-trace-id, the ids of jaeger are not padded.
+func ParseXTraceID(s string) (trace.SpanContext, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 4 {
+		return trace.SpanContext{}, false
+	}
+
+	traceID, err := trace.TraceIDFromHex(fmt.Sprintf("%032s", parts[0]))
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	spanID, err := trace.SpanIDFromHex(fmt.Sprintf("%016s", parts[1]))
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+
+	var flags trace.TraceFlags
+	if n, err := strconv.ParseUint(parts[3], 16, 8); err == nil && n&1 == 1 {
+		flags = trace.FlagsSampled
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		Remote:     true,
+	})
+	return sc, sc.IsValid()
+}
@@ -0,0 +1,29 @@
+package otelutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestXTraceIDPropagator(t *testing.T) {
+	// jaeger doesn't pad the ids.
+	carrier := propagation.MapCarrier{HeaderXTraceID: "f:a:0:1"}
+	sc := trace.SpanContextFromContext(XTraceIDPropagator{}.Extract(context.Background(), carrier))
+	assert.Equal(t, "0000000000000000000000000000000f", sc.TraceID().String())
+	assert.Equal(t, "000000000000000a", sc.SpanID().String())
+	assert.True(t, sc.IsSampled())
+
+	out := propagation.MapCarrier{}
+	Propagator().Inject(trace.ContextWithSpanContext(context.Background(), sc), out)
+	assert.Equal(t, "0000000000000000000000000000000f:000000000000000a:0:01", out[HeaderXTraceID])
+	assert.Equal(t, "00-0000000000000000000000000000000f-000000000000000a-01", out["traceparent"])
+
+	// traceparent is preferred.
+	carrier["traceparent"] = "00-0000000000000000000000000000000e-000000000000000a-01"
+	sc = trace.SpanContextFromContext(Propagator().Extract(context.Background(), carrier))
+	assert.Equal(t, "0000000000000000000000000000000e", sc.TraceID().String())
+}
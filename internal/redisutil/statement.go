@@ -0,0 +1,52 @@
+package redisutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formatter format the args of redis command to statement.
+type Formatter struct {
+	// Sanitize only keep the command name and key, drop the values.
+	Sanitize bool
+
+	// MaxArgLength truncate the arg longer than MaxArgLength when MaxArgLength > 0.
+	MaxArgLength int
+}
+
+// Statement format the args of redis command, like `set key value`.
+func Statement(args []interface{}) string {
+	return Formatter{}.Statement(args)
+}
+
+// Statement format the args of redis command by the formatter.
+func (f Formatter) Statement(args []interface{}) string {
+	if f.Sanitize && len(args) > 2 {
+		args = args[:2]
+	}
+
+	var b strings.Builder
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+
+		str := argString(arg)
+		if f.MaxArgLength > 0 && len(str) > f.MaxArgLength {
+			str = str[:f.MaxArgLength] + "..."
+		}
+		b.WriteString(str)
+	}
+	return b.String()
+}
+
+func argString(arg interface{}) string {
+	switch v := arg.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,16 @@
+package redisutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatement(t *testing.T) {
+	args := []interface{}{"set", "key", []byte("secret-value"), "ex", 10}
+
+	assert.Equal(t, "set key secret-value ex 10", Statement(args))
+	assert.Equal(t, "set key", Formatter{Sanitize: true}.Statement(args))
+	assert.Equal(t, "set key secret... ex 10", Formatter{MaxArgLength: 6}.Statement(args))
+	assert.Equal(t, "ping", Formatter{Sanitize: true}.Statement([]interface{}{"ping"}))
+}
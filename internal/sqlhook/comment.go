@@ -0,0 +1,95 @@
+package sqlhook
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Commenter is implemented by the Tracer which append sqlcommenter comment to the query,
+// return nil tags to keep the query.
+type Commenter interface {
+	CommentTags(ctx context.Context) map[string]string
+}
+
+type routeKey struct{}
+
+type withoutCommentKey struct{}
+
+// ContextWithRoute set the route of comment, like `/api/user/:id`.
+func ContextWithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// ContextWithoutComment don't comment the queries with the context.
+func ContextWithoutComment(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutCommentKey{}, true)
+}
+
+func (c *wrappedConn) comment(ctx context.Context, query string) string {
+	commenter, ok := c.tracer.(Commenter)
+	if !ok || ctx.Value(withoutCommentKey{}) != nil {
+		return query
+	}
+
+	tags := commenter.CommentTags(ctx)
+	if tags == nil {
+		return query
+	}
+	if route, ok := ctx.Value(routeKey{}).(string); ok {
+		tags["route"] = route
+	}
+	return Comment(query, tags)
+}
+
+// commentPrepare comment the prepared statement without the span and route of ctx, the statement
+// is reused by the later calls which have their own spans.
+func (c *wrappedConn) commentPrepare(ctx context.Context, query string) string {
+	if ctx.Value(withoutCommentKey{}) != nil {
+		return query
+	}
+	return c.comment(context.Background(), query)
+}
+
+// Comment append the tags to query by sqlcommenter spec, like `select 1 /*application='api',route='%2Fuser'*/`,
+// the query which already has a comment is not changed.
+func Comment(query string, tags map[string]string) string {
+	if strings.Contains(query, "/*") || strings.Contains(query, "--") {
+		return query
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key, val := range tags {
+		if val != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return query
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(query, " ;"))
+	b.WriteString(" /*")
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(escapeComment(key))
+		b.WriteString("='")
+		b.WriteString(strings.ReplaceAll(escapeComment(tags[key]), "'", `\'`))
+		b.WriteByte('\'')
+	}
+	b.WriteString("*/")
+	if strings.HasSuffix(strings.TrimRight(query, " "), ";") {
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+// escapeComment url encode by RFC 3986, the space is encoded as %20.
+func escapeComment(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,193 @@
+package sqlhook
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+var (
+	errNamedArgs                         = errors.New("sql: driver does not support the use of Named Parameters")
+	errIsolationLevel                    = errors.New("sql: driver does not support non-default isolation level")
+	errReadOnlyTx                        = errors.New("sql: driver does not support read-only transactions")
+	_                                    = driver.Conn(&wrappedConn{})
+	_                 driver.ConnBeginTx = &wrappedConn{}
+)
+
+// wrappedConn implements all optional interfaces, return driver.ErrSkip or the default
+// behavior of database/sql when the origin conn doesn't implement it.
+type wrappedConn struct {
+	driver.Conn
+	tracer Tracer
+}
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	ctx, span := c.tracer.Start(ctx, OpPrepare, query, nil)
+	commented := c.commentPrepare(ctx, query)
+
+	var (
+		st  driver.Stmt
+		err error
+	)
+	if cpc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		st, err = cpc.PrepareContext(ctx, commented)
+	} else {
+		st, err = c.Conn.Prepare(commented)
+	}
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+	return wrapStmt(st, c.Conn, query, c.tracer), nil
+}
+
+func (c *wrappedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	// the commit and rollback spans are siblings of the begin span, keep the caller ctx for them.
+	spanCtx, span := c.tracer.Start(ctx, OpBegin, "", nil)
+
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if cbt, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = cbt.BeginTx(spanCtx, opts)
+	} else if opts.Isolation != 0 {
+		err = errIsolationLevel
+	} else if opts.ReadOnly {
+		err = errReadOnlyTx
+	} else {
+		tx, err = c.Conn.Begin() //nolint
+	}
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedTx{Tx: tx, ctx: ctx, tracer: c.tracer}, nil
+}
+
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, isExecerContext := c.Conn.(driver.ExecerContext)
+	legacy, isExecer := c.Conn.(driver.Execer) //nolint
+	if !isExecerContext && !isExecer {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := c.tracer.Start(ctx, OpExec, query, args)
+	query = c.comment(ctx, query)
+
+	var (
+		res driver.Result
+		err error
+	)
+	if isExecerContext {
+		res, err = execer.ExecContext(ctx, query, args)
+	} else {
+		var vals []driver.Value
+		vals, err = namedValueToValue(args)
+		if err == nil {
+			res, err = legacy.Exec(query, vals)
+		}
+	}
+	endExecSpan(span, res, err)
+	return res, err
+}
+
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, isQueryerContext := c.Conn.(driver.QueryerContext)
+	legacy, isQueryer := c.Conn.(driver.Queryer) //nolint
+	if !isQueryerContext && !isQueryer {
+		return nil, driver.ErrSkip
+	}
+
+	cctx, span := c.tracer.Start(ctx, OpQuery, query, args)
+	commented := c.comment(cctx, query)
+
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if isQueryerContext {
+		rows, err = queryer.QueryContext(cctx, commented, args)
+	} else {
+		var vals []driver.Value
+		vals, err = namedValueToValue(args)
+		if err == nil {
+			rows, err = legacy.Query(commented, vals)
+		}
+	}
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+	return wrapRows(ctx, rows, query, c.tracer), nil
+}
+
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	pinger, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, OpPing, "", nil)
+	err := pinger.Ping(ctx)
+	span.End(err)
+	return err
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *wrappedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func endExecSpan(span Span, res driver.Result, err error) {
+	if err == nil && res != nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			span.SetRows(n)
+		}
+	}
+	span.End(err)
+}
+
+type wrappedTx struct {
+	driver.Tx
+	ctx    context.Context
+	tracer Tracer
+}
+
+func (tx *wrappedTx) Commit() error {
+	_, span := tx.tracer.Start(tx.ctx, OpCommit, "", nil)
+	err := tx.Tx.Commit()
+	span.End(err)
+	return err
+}
+
+func (tx *wrappedTx) Rollback() error {
+	_, span := tx.tracer.Start(tx.ctx, OpRollback, "", nil)
+	err := tx.Tx.Rollback()
+	span.End(err)
+	return err
+}
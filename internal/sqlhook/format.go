@@ -0,0 +1,123 @@
+package sqlhook
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Formatter format the statement and args of query for span.
+type Formatter struct {
+	// Args format the args of query, the args are not recorded by default.
+	Args bool
+
+	// Redact replace the literals in statement and the args with `?`.
+	Redact bool
+}
+
+// Statement return the statement of query, the literals are replaced when Redact is true.
+func (f Formatter) Statement(query string) string {
+	if !f.Redact {
+		return query
+	}
+	return Redact(query)
+}
+
+// FormatArgs format the args of query, like `[1, "name"]`, return empty string when Args is false.
+func (f Formatter) FormatArgs(args []driver.NamedValue) string {
+	if !f.Args || len(args) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if arg.Name != "" {
+			b.WriteString(arg.Name)
+			b.WriteByte('=')
+		}
+		if f.Redact {
+			b.WriteByte('?')
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case string:
+			fmt.Fprintf(&b, "%q", v)
+		case []byte:
+			fmt.Fprintf(&b, "%q", v)
+		default:
+			fmt.Fprint(&b, v)
+		}
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// Redact replace the string and number literals in query with `?`,
+// like `select * from user where name = 'bob' and age > 10` to `select * from user where name = ? and age > ?`.
+func Redact(query string) string {
+	var (
+		b    strings.Builder
+		prev byte
+	)
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			// skip the quoted literal, the quote is escaped by doubling or backslash.
+			j := i + 1
+			for ; j < len(query); j++ {
+				if query[j] == '\\' {
+					j++
+					continue
+				}
+				if query[j] == c {
+					if j+1 < len(query) && query[j+1] == c {
+						j++
+						continue
+					}
+					break
+				}
+			}
+			if c == '"' {
+				// double quoted identifier in postgres, keep it.
+				end := j + 1
+				if end > len(query) {
+					end = len(query)
+				}
+				b.WriteString(query[i:end])
+			} else {
+				b.WriteByte('?')
+			}
+			i = j
+			prev = c
+
+		case isDigit(c) && !isIdent(prev):
+			j := i + 1
+			for j < len(query) && (isDigit(query[j]) || query[j] == '.') {
+				j++
+			}
+			b.WriteByte('?')
+			i = j - 1
+			prev = '0'
+
+		default:
+			b.WriteByte(c)
+			prev = c
+		}
+	}
+	return b.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c == '$' || c == '?' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,47 @@
+package sqlhook
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	cases := map[string]string{
+		"select * from user where id = 10":                       "select * from user where id = ?",
+		"select * from user where name = 'bob''s' and age > 1.5": "select * from user where name = ? and age > ?",
+		`select "t1".id from t1 where id = $1 and k = 'a\'b'`:    `select "t1".id from t1 where id = $1 and k = ?`,
+		"insert into t2 (a, b) values (?, 'x')":                  "insert into t2 (a, b) values (?, ?)",
+	}
+	for query, expected := range cases {
+		assert.Equal(t, expected, Redact(query))
+	}
+}
+
+func TestFormatArgs(t *testing.T) {
+	args := []driver.NamedValue{
+		{Ordinal: 1, Value: int64(1)},
+		{Ordinal: 2, Name: "name", Value: "bob"},
+	}
+
+	assert.Equal(t, "", Formatter{}.FormatArgs(args))
+	assert.Equal(t, `[1, name="bob"]`, Formatter{Args: true}.FormatArgs(args))
+	assert.Equal(t, `[?, name=?]`, Formatter{Args: true, Redact: true}.FormatArgs(args))
+}
+
+func TestComment(t *testing.T) {
+	tags := map[string]string{
+		"route":       "/param*d",
+		"application": "it's api",
+		"traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01",
+		"empty":       "",
+	}
+
+	assert.Equal(t,
+		`select 1 /*application='it%27s%20api',route='%2Fparam%2Ad',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/;`,
+		Comment("select 1;", tags),
+	)
+	assert.Equal(t, "select /* hint */ 1", Comment("select /* hint */ 1", tags))
+	assert.Equal(t, "select 1", Comment("select 1", nil))
+}
@@ -0,0 +1,121 @@
+// Package sqlhook wrap database/sql/driver, the spans are created by the Tracer.
+package sqlhook
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// the operations of driver.
+const (
+	OpConnect  = "sql.connect"
+	OpPing     = "sql.ping"
+	OpPrepare  = "sql.prepare"
+	OpExec     = "sql.exec"
+	OpQuery    = "sql.query"
+	OpBegin    = "sql.begin"
+	OpCommit   = "sql.commit"
+	OpRollback = "sql.rollback"
+	OpRows     = "sql.rows"
+)
+
+// Tracer start span for the operation of driver.
+type Tracer interface {
+	Start(ctx context.Context, op string, query string, args []driver.NamedValue) (context.Context, Span)
+}
+
+// Span is finished by End, the driver.ErrSkip is not an error.
+type Span interface {
+	// SetRows record the rows affected by exec, or the rows iterated by rows.
+	SetRows(n int64)
+	End(err error)
+}
+
+// WrapDriver return a driver which creates spans by tracer.
+func WrapDriver(d driver.Driver, tracer Tracer) driver.Driver {
+	return &wrappedDriver{Driver: d, tracer: tracer}
+}
+
+// WrapConnector return a connector which creates spans by tracer.
+func WrapConnector(c driver.Connector, tracer Tracer) driver.Connector {
+	return &wrappedConnector{
+		Connector: c,
+		driver:    &wrappedDriver{Driver: c.Driver(), tracer: tracer},
+		tracer:    tracer,
+	}
+}
+
+type wrappedDriver struct {
+	driver.Driver
+	tracer Tracer
+}
+
+var _ driver.DriverContext = &wrappedDriver{}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	cn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{Conn: cn, tracer: d.tracer}, nil
+}
+
+func (d *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &wrappedConnector{Connector: c, driver: d, tracer: d.tracer}, nil
+	}
+
+	return &dsnConnector{dsn: name, driver: d}, nil
+}
+
+type wrappedConnector struct {
+	driver.Connector
+	driver *wrappedDriver
+	tracer Tracer
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	ctx, span := c.tracer.Start(ctx, OpConnect, "", nil)
+	cn, err := c.Connector.Connect(ctx)
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{Conn: cn, tracer: c.tracer}, nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector is used when the driver doesn't implement driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver *wrappedDriver
+}
+
+func (c *dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	_, span := c.driver.tracer.Start(ctx, OpConnect, "", nil)
+	cn, err := c.driver.Open(c.dsn)
+	span.End(err)
+	return cn, err
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func namedValueToValue(named []driver.NamedValue) ([]driver.Value, error) {
+	args := make([]driver.Value, len(named))
+	for i, nv := range named {
+		if nv.Name != "" {
+			return nil, errNamedArgs
+		}
+		args[i] = nv.Value
+	}
+	return args, nil
+}
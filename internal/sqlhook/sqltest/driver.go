@@ -0,0 +1,126 @@
+// Package sqltest provide an in-memory fake driver for the tests of database/sql.
+package sqltest
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ErrFake is returned by the query contains `error`.
+var ErrFake = errors.New("sqltest: fake error")
+
+// Driver is a fake driver, all queries return Rows, the query contains `error` fails.
+type Driver struct {
+	// Rows returned by query, the columns are `id` and `name`.
+	Rows [][]driver.Value
+
+	mu      sync.Mutex
+	queries []string
+}
+
+// Queries return the queries executed by the driver.
+func (d *Driver) Queries() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.queries...)
+}
+
+func (d *Driver) record(query string) error {
+	d.mu.Lock()
+	d.queries = append(d.queries, query)
+	d.mu.Unlock()
+
+	if strings.Contains(query, "error") {
+		return ErrFake
+	}
+	return nil
+}
+
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	return &conn{driver: d}, nil
+}
+
+type conn struct {
+	driver *Driver
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *conn) Commit() error {
+	return c.driver.record("COMMIT")
+}
+
+func (c *conn) Rollback() error {
+	return c.driver.record("ROLLBACK")
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.driver.record(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.driver.record(query); err != nil {
+		return nil, err
+	}
+	return &rows{values: c.driver.Rows}, nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, nil)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, nil)
+}
+
+type rows struct {
+	values [][]driver.Value
+	pos    int
+}
+
+func (r *rows) Columns() []string {
+	return []string{"id", "name"}
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.pos])
+	r.pos++
+	return nil
+}
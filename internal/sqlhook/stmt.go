@@ -0,0 +1,97 @@
+package sqlhook
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+type wrappedStmt struct {
+	driver.Stmt
+	conn   driver.Conn
+	query  string
+	tracer Tracer
+}
+
+// stmtWithColumnConverter keep the driver.ColumnConverter of origin stmt.
+type stmtWithColumnConverter struct {
+	*wrappedStmt
+	driver.ColumnConverter //nolint
+}
+
+func wrapStmt(st driver.Stmt, conn driver.Conn, query string, tracer Tracer) driver.Stmt {
+	ws := &wrappedStmt{Stmt: st, conn: conn, query: query, tracer: tracer}
+	if cc, ok := st.(driver.ColumnConverter); ok { //nolint
+		return &stmtWithColumnConverter{wrappedStmt: ws, ColumnConverter: cc}
+	}
+	return ws
+}
+
+func (s *wrappedStmt) Exec(args []driver.Value) (driver.Result, error) { //nolint
+	return s.ExecContext(context.Background(), valueToNamedValue(args))
+}
+
+func (s *wrappedStmt) Query(args []driver.Value) (driver.Rows, error) { //nolint
+	return s.QueryContext(context.Background(), valueToNamedValue(args))
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span := s.tracer.Start(ctx, OpExec, s.query, args)
+
+	var (
+		res driver.Result
+		err error
+	)
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = execer.ExecContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		vals, err = namedValueToValue(args)
+		if err == nil {
+			res, err = s.Stmt.Exec(vals) //nolint
+		}
+	}
+	endExecSpan(span, res, err)
+	return res, err
+}
+
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	cctx, span := s.tracer.Start(ctx, OpQuery, s.query, args)
+
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(cctx, args)
+	} else {
+		var vals []driver.Value
+		vals, err = namedValueToValue(args)
+		if err == nil {
+			rows, err = s.Stmt.Query(vals) //nolint
+		}
+	}
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+	return wrapRows(ctx, rows, s.query, s.tracer), nil
+}
+
+// CheckNamedValue database/sql only use the checker of stmt if stmt implements it.
+func (s *wrappedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	if checker, ok := s.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func valueToNamedValue(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
@@ -0,0 +1,86 @@
+// Package stackutil caches the caller frames by pc, the runtime.CallersFrames is expensive on the hot path.
+package stackutil
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	stackCache sync.Map
+)
+
+// Entry the caller frame with the trimmed names.
+type Entry struct {
+	Frame runtime.Frame
+
+	Function string // (*obj).run2
+	File     string // controller/obj.go
+	Line     int
+}
+
+// Caller returns the cached frame of the caller, skip 0 is the caller of Caller.
+func Caller(skip int) Entry {
+	// get stack pc offset
+	rpc := [1]uintptr{}
+	n := runtime.Callers(skip+2, rpc[:])
+	if n < 1 {
+		return Entry{}
+	}
+
+	pc := rpc[0]
+	item, ok := stackCache.Load(pc)
+	if ok {
+		return item.(Entry)
+	}
+
+	// get stack frame
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	// get func name
+	var funcName string
+	funcPC := runtime.FuncForPC(pc)
+	if funcPC != nil {
+		funcName = TrimFuncname(funcPC.Name())
+	}
+
+	si := Entry{
+		Frame:    frame,
+		Function: funcName,
+		File:     TrimFilename(frame.File),
+		Line:     frame.Line,
+	}
+	stackCache.Store(pc, si)
+	return si
+}
+
+// TrimFilename /go/src/ocean/internal/controller/obj.go => controller/obj.go
+func TrimFilename(file string) string {
+	return trimString(file, '/', 2)
+}
+
+// TrimFuncname git.github.com/ocean/internal/controller.(*obj).run2 => (*obj).run2
+func TrimFuncname(name string) string {
+	i := strings.LastIndex(name, "/")
+	name = name[i+1:]
+
+	i = strings.Index(name, ".")
+	return name[i+1:]
+}
+
+// trimString only retain shrot 2 level.
+func trimString(str string, seq byte, level int) string {
+	// get package name
+
+	n := 0
+	for i := len(str) - 1; i > 0; i-- {
+		if str[i] == seq {
+			n++
+			if n >= 2 {
+				return str[i+1:]
+			}
+		}
+	}
+	return str
+}
@@ -0,0 +1,106 @@
+// Package taskgroup run tasks in goroutines with bounded concurrency, the first error cancels the others.
+package taskgroup
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Task the state of task passed to the function of task.
+type Task struct {
+	Index int
+	Key   string
+
+	// QueueWait the duration waiting for the free worker.
+	QueueWait time.Duration
+
+	// Saturated the task waited because all workers were busy.
+	Saturated bool
+
+	// Canceled the context is canceled before the task runs, the task should be skipped.
+	Canceled bool
+}
+
+// Group is like errgroup.Group with the limit of concurrency.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{}
+
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+
+	index     int64
+	saturated int32
+}
+
+// New return the group which run at most limit tasks concurrently, no limit when limit <= 0.
+func New(ctx context.Context, limit int) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group{ctx: ctx, cancel: cancel}
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+	return g
+}
+
+// Context return the context canceled by the first error or Wait.
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// Saturated return whether any task waited for the free worker.
+func (g *Group) Saturated() bool {
+	return atomic.LoadInt32(&g.saturated) == 1
+}
+
+// Go run fn in a new goroutine, fn waits for the free worker when the limit is reached.
+func (g *Group) Go(key string, fn func(ctx context.Context, task *Task) error) {
+	task := &Task{
+		Index: int(atomic.AddInt64(&g.index, 1) - 1),
+		Key:   key,
+	}
+	enqueued := time.Now()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		if g.sem != nil {
+			acquired := true
+			select {
+			case g.sem <- struct{}{}:
+			default:
+				task.Saturated = true
+				atomic.StoreInt32(&g.saturated, 1)
+				select {
+				case g.sem <- struct{}{}:
+				case <-g.ctx.Done():
+					acquired = false
+				}
+			}
+			if acquired {
+				defer func() { <-g.sem }()
+			}
+		}
+		task.QueueWait = time.Since(enqueued)
+		task.Canceled = g.ctx.Err() != nil
+
+		if err := fn(g.ctx, task); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+// Wait blocks until all tasks return, then return the first error.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
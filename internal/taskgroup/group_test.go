@@ -0,0 +1,61 @@
+package taskgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupLimit(t *testing.T) {
+	g := New(context.Background(), 2)
+
+	var running, maxRunning int32
+	for i := 0; i < 6; i++ {
+		g.Go("", func(ctx context.Context, task *Task) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+
+	assert.Nil(t, g.Wait())
+	assert.Equal(t, int32(2), maxRunning)
+	assert.True(t, g.Saturated())
+}
+
+func TestGroupCancel(t *testing.T) {
+	g := New(context.Background(), 1)
+
+	failed := errors.New("failed")
+	var canceled int32
+	started := make(chan struct{})
+	g.Go("first", func(ctx context.Context, task *Task) error {
+		close(started)
+		time.Sleep(10 * time.Millisecond)
+		return failed
+	})
+	<-started
+	for i := 0; i < 3; i++ {
+		g.Go("", func(ctx context.Context, task *Task) error {
+			if task.Canceled {
+				atomic.AddInt32(&canceled, 1)
+			}
+			return nil
+		})
+	}
+
+	assert.Equal(t, failed, g.Wait())
+	assert.Equal(t, int32(3), canceled)
+	assert.NotNil(t, g.Context().Err())
+}
@@ -0,0 +1,58 @@
+// Package kafkatrace propagate the span context by the headers of kafka message, support sarama and kafka-go,
+// the span context is injected by the opentracing tracer, x-trace-id and w3c traceparent.
+package kafkatrace
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rfyiamcool/go-tracer/internal/jaegerutil"
+)
+
+const consumeOperation = "kafka.consume"
+
+// headersCarrier is implemented by the headers of message.
+type headersCarrier interface {
+	opentracing.TextMapWriter
+	opentracing.TextMapReader
+}
+
+func inject(ctx context.Context, carrier headersCarrier) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		jaegerutil.Inject(span, carrier)
+	}
+}
+
+type consumerMessage struct {
+	topic     string
+	partition int
+	offset    int64
+	group     string
+	component string
+}
+
+// startConsumerSpan start span which follows from the producer, and is child of the span in context.
+func startConsumerSpan(ctx context.Context, carrier headersCarrier, msg consumerMessage) (opentracing.Span, context.Context) {
+	opts := []opentracing.StartSpanOption{
+		ext.SpanKindConsumer,
+		opentracing.Tags{
+			string(ext.Component):             msg.component,
+			string(ext.MessageBusDestination): msg.topic,
+			"kafka.partition":                 msg.partition,
+			"kafka.offset":                    msg.offset,
+		},
+	}
+	if msg.group != "" {
+		opts = append(opts, opentracing.Tag{Key: "kafka.consumer_group", Value: msg.group})
+	}
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+	if producer, err := jaegerutil.Extract(opentracing.GlobalTracer(), carrier); err == nil {
+		opts = append(opts, opentracing.FollowsFrom(producer))
+	}
+
+	span := opentracing.GlobalTracer().StartSpan(consumeOperation, opts...)
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
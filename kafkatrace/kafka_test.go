@@ -0,0 +1,101 @@
+package kafkatrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/opentracing/opentracing-go"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+)
+
+func newTestTracer(t *testing.T) func() {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	opentracing.SetGlobalTracer(jtracer)
+	return func() {
+		closer.Close()
+		opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+	}
+}
+
+func assertFollowsFrom(t *testing.T, producer, consumer opentracing.Span) {
+	psc := producer.(*jaeger.Span).SpanContext()
+	csc := consumer.(*jaeger.Span).SpanContext()
+	assert.Equal(t, psc.TraceID(), csc.TraceID())
+
+	refs := consumer.(*jaeger.Span).References()
+	assert.Len(t, refs, 1)
+	assert.Equal(t, opentracing.FollowsFromRef, refs[0].Type)
+	assert.Equal(t, psc.SpanID(), refs[0].ReferencedContext.(jaeger.SpanContext).SpanID())
+}
+
+func TestSarama(t *testing.T) {
+	defer newTestTracer(t)()
+
+	producerSpan, ctx := opentracing.StartSpanFromContext(context.Background(), "produce")
+	defer producerSpan.Finish()
+
+	var headers []*sarama.RecordHeader
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		for i := range msg.Headers {
+			headers = append(headers, &msg.Headers[i])
+		}
+		return nil
+	})
+
+	msg := &sarama.ProducerMessage{Topic: "orders", Value: sarama.StringEncoder("value")}
+	InjectSarama(ctx, msg)
+	_, _, err := producer.SendMessage(msg)
+	assert.Nil(t, err)
+	assert.Nil(t, producer.Close())
+
+	keys := make(map[string]string)
+	for _, header := range headers {
+		keys[string(header.Key)] = string(header.Value)
+	}
+	assert.NotEmpty(t, keys["uber-trace-id"])
+	assert.Equal(t, producerSpan.(*jaeger.Span).SpanContext().String(), keys["x-trace-id"])
+	assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`, keys["traceparent"])
+
+	span, _ := StartSaramaConsumerSpan(context.Background(), "group", &sarama.ConsumerMessage{
+		Topic: "orders", Partition: 1, Offset: 10, Headers: headers,
+	})
+	span.Finish()
+
+	assertFollowsFrom(t, producerSpan, span)
+	tags := span.(*jaeger.Span).Tags()
+	assert.Equal(t, "orders", tags["message_bus.destination"])
+	assert.Equal(t, 1, tags["kafka.partition"])
+	assert.Equal(t, int64(10), tags["kafka.offset"])
+	assert.Equal(t, "group", tags["kafka.consumer_group"])
+}
+
+func TestKafkaGo(t *testing.T) {
+	defer newTestTracer(t)()
+
+	producerSpan, ctx := opentracing.StartSpanFromContext(context.Background(), "produce")
+	defer producerSpan.Finish()
+
+	msg := kafka.Message{Topic: "orders", Value: []byte("value")}
+	InjectKafka(ctx, &msg)
+
+	// only w3c traceparent, like the message produced by other language.
+	received := kafka.Message{Topic: "orders", Partition: 2, Offset: 20}
+	for _, header := range msg.Headers {
+		if header.Key == "traceparent" {
+			received.Headers = append(received.Headers, header)
+		}
+	}
+	assert.Len(t, received.Headers, 1)
+
+	span, cctx := StartKafkaConsumerSpan(context.Background(), "", &received)
+	span.Finish()
+
+	assertFollowsFrom(t, producerSpan, span)
+	assert.Equal(t, span, opentracing.SpanFromContext(cctx))
+	assert.Nil(t, span.(*jaeger.Span).Tags()["kafka.consumer_group"])
+}
@@ -0,0 +1,50 @@
+package kafkatrace
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/segmentio/kafka-go"
+)
+
+// InjectKafka inject the span context of ctx into the headers of kafka-go message before writing.
+//
+//	msg := kafka.Message{Value: value}
+//	kafkatrace.InjectKafka(ctx, &msg)
+//	writer.WriteMessages(ctx, msg)
+func InjectKafka(ctx context.Context, msg *kafka.Message) {
+	inject(ctx, (*kafkaCarrier)(msg))
+}
+
+// StartKafkaConsumerSpan start consumer span which follows from the producer span of kafka-go message,
+// the span is tagged with topic, partition, offset and consumer group.
+func StartKafkaConsumerSpan(ctx context.Context, group string, msg *kafka.Message) (opentracing.Span, context.Context) {
+	return startConsumerSpan(ctx, (*kafkaCarrier)(msg), consumerMessage{
+		topic:     msg.Topic,
+		partition: msg.Partition,
+		offset:    msg.Offset,
+		group:     group,
+		component: "kafka-go",
+	})
+}
+
+type kafkaCarrier kafka.Message
+
+func (c *kafkaCarrier) Set(key, val string) {
+	for i, header := range c.Headers {
+		if header.Key == key {
+			c.Headers[i].Value = []byte(val)
+			return
+		}
+	}
+	c.Headers = append(c.Headers, kafka.Header{Key: key, Value: []byte(val)})
+}
+
+func (c *kafkaCarrier) ForeachKey(handler func(key, val string) error) error {
+	for _, header := range c.Headers {
+		if err := handler(header.Key, string(header.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package kafkatrace
+
+import (
+	"context"
+
+	"github.com/Shopify/sarama"
+	"github.com/opentracing/opentracing-go"
+)
+
+// InjectSarama inject the span context of ctx into the headers of message before sending.
+//
+//	msg := &sarama.ProducerMessage{Topic: topic, Value: sarama.StringEncoder(value)}
+//	kafkatrace.InjectSarama(ctx, msg)
+//	producer.SendMessage(msg)
+func InjectSarama(ctx context.Context, msg *sarama.ProducerMessage) {
+	inject(ctx, (*saramaProducerCarrier)(msg))
+}
+
+// StartSaramaConsumerSpan start consumer span which follows from the producer span of message,
+// the span is tagged with topic, partition, offset and consumer group.
+func StartSaramaConsumerSpan(ctx context.Context, group string, msg *sarama.ConsumerMessage) (opentracing.Span, context.Context) {
+	return startConsumerSpan(ctx, saramaConsumerCarrier(msg.Headers), consumerMessage{
+		topic:     msg.Topic,
+		partition: int(msg.Partition),
+		offset:    msg.Offset,
+		group:     group,
+		component: "sarama",
+	})
+}
+
+type saramaProducerCarrier sarama.ProducerMessage
+
+func (c *saramaProducerCarrier) Set(key, val string) {
+	for i, header := range c.Headers {
+		if string(header.Key) == key {
+			c.Headers[i].Value = []byte(val)
+			return
+		}
+	}
+	c.Headers = append(c.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(val)})
+}
+
+func (c *saramaProducerCarrier) ForeachKey(handler func(key, val string) error) error {
+	for _, header := range c.Headers {
+		if err := handler(string(header.Key), string(header.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type saramaConsumerCarrier []*sarama.RecordHeader
+
+func (c saramaConsumerCarrier) Set(key, val string) {}
+
+func (c saramaConsumerCarrier) ForeachKey(handler func(key, val string) error) error {
+	for _, header := range c {
+		if header == nil {
+			continue
+		}
+		if err := handler(string(header.Key), string(header.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Package logrustrace correlate the logrus logs with the opentracing spans, add trace_id and
+// span_id of the entry context, and mirror the logs to the span optionally.
+package logrustrace
+
+import (
+	"github.com/opentracing/opentracing-go/log"
+	tracer "github.com/rfyiamcool/go-tracer"
+	"github.com/rfyiamcool/go-tracer/internal/logutil"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook add trace_id and span_id to the entry which has context.
+type Hook struct {
+	spanLog   bool
+	spanLevel logrus.Level
+}
+
+var _ logrus.Hook = &Hook{}
+
+type Option func(*Hook)
+
+// WithSpanLog mirror the entries at level or above to the span logs of context, like logrus.WarnLevel.
+func WithSpanLog(level logrus.Level) Option {
+	return func(hook *Hook) {
+		hook.spanLog = true
+		hook.spanLevel = level
+	}
+}
+
+// NewHook creates the hook, the context of entry is set by WithContext.
+//
+//	logrus.AddHook(logrustrace.NewHook(logrustrace.WithSpanLog(logrus.WarnLevel)))
+//	logrus.WithContext(ctx).WithField("id", id).Info("get user")
+func NewHook(opts ...Option) *Hook {
+	hook := &Hook{}
+	for _, opt := range opts {
+		opt(hook)
+	}
+	return hook
+}
+
+// Levels implements logrus.Hook.
+func (hook *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (hook *Hook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	span := tracer.SpanFromContext(entry.Context)
+	if traceID, spanID := tracer.GetTraceSpanIDs(span); traceID != "" {
+		entry.Data[logutil.TraceIDKey] = traceID
+		entry.Data[logutil.SpanIDKey] = spanID
+	}
+
+	// the lower level is more severe in logrus.
+	if span == nil || !hook.spanLog || entry.Level > hook.spanLevel {
+		return nil
+	}
+
+	fields := []log.Field{
+		log.String("event", logutil.EventLog),
+		log.String(logutil.LevelKey, entry.Level.String()),
+		log.String(logutil.MessageKey, entry.Message),
+	}
+	for _, k := range logutil.SortedKeys(entry.Data) {
+		if k == logutil.TraceIDKey || k == logutil.SpanIDKey {
+			continue
+		}
+		fields = append(fields, log.Object(k, entry.Data[k]))
+	}
+	span.LogFields(fields...)
+	return nil
+}
@@ -0,0 +1,53 @@
+package logrustrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestHook(t *testing.T) {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+
+	span := jtracer.StartSpan("get-user")
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+	sc := span.Context().(jaeger.SpanContext)
+
+	logger, hook := test.NewNullLogger()
+	logger.AddHook(NewHook(WithSpanLog(logrus.WarnLevel)))
+
+	logger.WithContext(ctx).WithField("id", 1).Info("get user")
+	logger.WithContext(ctx).WithField("cost", 300).Warn("slow query")
+	logger.WithContext(context.Background()).Warn("no span")
+	logger.Error("no context")
+
+	entries := hook.AllEntries()
+	assert.Len(t, entries, 4)
+	for _, entry := range entries[:2] {
+		assert.Equal(t, sc.TraceID().String(), entry.Data["trace_id"])
+		assert.Equal(t, sc.SpanID().String(), entry.Data["span_id"])
+	}
+	assert.Equal(t, 1, entries[0].Data["id"])
+	assert.Empty(t, entries[2].Data)
+	assert.Empty(t, entries[3].Data)
+
+	// only the warn log with span is mirrored.
+	records := span.(*jaeger.Span).Logs()
+	assert.Len(t, records, 1)
+
+	fields := make(map[string]interface{})
+	for _, f := range records[0].Fields {
+		fields[f.Key()] = f.Value()
+	}
+	assert.Equal(t, "log", fields["event"])
+	assert.Equal(t, "warning", fields["log.severity"])
+	assert.Equal(t, "slow query", fields["log.message"])
+	assert.Equal(t, 300, fields["cost"])
+	assert.Nil(t, fields["trace_id"])
+}
@@ -0,0 +1,112 @@
+// Package mongotrace trace the commands of mongo-driver by opentracing.
+package mongotrace
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/rfyiamcool/go-tracer/internal/mongoutil"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+type monitor struct {
+	tracer opentracing.Tracer
+
+	statement    bool
+	skipCommands map[string]bool
+	spans        mongoutil.SpanMap
+}
+
+type Option func(*monitor)
+
+// WithoutStatement don't tag db.statement, the values of statement are always sanitized.
+func WithoutStatement() Option {
+	return func(m *monitor) {
+		m.statement = false
+	}
+}
+
+// WithSkipCommands don't trace the noisy commands, like ping, hello.
+func WithSkipCommands(names ...string) Option {
+	return func(m *monitor) {
+		for _, name := range names {
+			m.skipCommands[name] = true
+		}
+	}
+}
+
+// NewMonitor creates a new command monitor which collect spans using the provided tracer,
+// use the global tracer when tracer is nil.
+//
+//	opts := options.Client().ApplyURI(uri).SetMonitor(mongotrace.NewMonitor(tracer.GetTracer()))
+//	client, err := mongo.Connect(ctx, opts)
+func NewMonitor(tracer opentracing.Tracer, opts ...Option) *event.CommandMonitor {
+	if tracer == nil {
+		tracer = opentracing.GlobalTracer()
+	}
+
+	m := &monitor{
+		tracer:       tracer,
+		statement:    true,
+		skipCommands: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+func (m *monitor) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	if m.skipCommands[evt.CommandName] {
+		return
+	}
+
+	collection := mongoutil.Collection(evt.Command)
+	operationName := evt.CommandName
+	if collection != "" {
+		operationName = collection + "." + evt.CommandName
+	}
+
+	tags := opentracing.Tags{
+		string(ext.DBType):     "mongo",
+		string(ext.DBInstance): evt.DatabaseName,
+		"db.mongo.command":     evt.CommandName,
+		"db.mongo.collection":  collection,
+	}
+	host, port := mongoutil.PeerHostPort(evt.ConnectionID)
+	tags[string(ext.PeerHostname)] = host
+	if port > 0 {
+		tags[string(ext.PeerPort)] = uint16(port)
+	}
+	if m.statement {
+		tags[string(ext.DBStatement)] = mongoutil.Statement(evt.Command)
+	}
+
+	span, _ := opentracing.StartSpanFromContextWithTracer(ctx, m.tracer, operationName, tags, ext.SpanKindRPCClient)
+	m.spans.Store(evt.ConnectionID, evt.RequestID, span)
+}
+
+func (m *monitor) succeeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	if span, ok := m.spans.LoadAndDelete(evt.ConnectionID, evt.RequestID); ok {
+		span.(opentracing.Span).Finish()
+	}
+}
+
+func (m *monitor) failed(ctx context.Context, evt *event.CommandFailedEvent) {
+	val, ok := m.spans.LoadAndDelete(evt.ConnectionID, evt.RequestID)
+	if !ok {
+		return
+	}
+
+	span := val.(opentracing.Span)
+	ext.Error.Set(span, true)
+	span.LogFields(log.String("event", "error"), log.String("message", evt.Failure))
+	span.Finish()
+}
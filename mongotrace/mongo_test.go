@@ -0,0 +1,55 @@
+package mongotrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestMonitor(t *testing.T) {
+	mtracer := mocktracer.New()
+	monitor := NewMonitor(mtracer, WithSkipCommands("ping"))
+
+	parent := mtracer.StartSpan("parent")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+
+	command, _ := bson.Marshal(bson.D{{Key: "find", Value: "users"}, {Key: "filter", Value: bson.D{{Key: "name", Value: "bob"}}}})
+	started := func(name string, requestID int64) {
+		monitor.Started(ctx, &event.CommandStartedEvent{
+			Command:      command,
+			DatabaseName: "test",
+			CommandName:  name,
+			RequestID:    requestID,
+			ConnectionID: "localhost:27017[-1]",
+		})
+	}
+	finished := func(name string, requestID int64) event.CommandFinishedEvent {
+		return event.CommandFinishedEvent{CommandName: name, RequestID: requestID, ConnectionID: "localhost:27017[-1]"}
+	}
+
+	started("find", 1)
+	started("find", 2)
+	started("ping", 3)
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished("find", 2), Failure: "timeout"})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished("find", 1)})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished("ping", 3)})
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 2)
+
+	failed, succeeded := spans[0], spans[1]
+	assert.Equal(t, true, failed.Tag("error"))
+	assert.Nil(t, succeeded.Tag("error"))
+
+	assert.Equal(t, "users.find", succeeded.OperationName)
+	assert.Equal(t, parent.(*mocktracer.MockSpan).SpanContext.SpanID, succeeded.ParentID)
+	assert.Equal(t, "users", succeeded.Tag("db.mongo.collection"))
+	assert.Equal(t, "test", succeeded.Tag("db.instance"))
+	assert.Equal(t, "localhost", succeeded.Tag("peer.hostname"))
+	assert.Equal(t, `{"find":"users","filter":{"name":"?"}}`, succeeded.Tag("db.statement"))
+}
@@ -0,0 +1,143 @@
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var (
+	_ propagation.TextMapCarrier = MapCarrier{}
+	_ propagation.TextMapCarrier = BytesMapCarrier{}
+	_ propagation.TextMapCarrier = &HeadersCarrier{}
+	_ propagation.TextMapCarrier = TableCarrier{}
+)
+
+// Inject inject the span context of ctx into carrier by GetCarrierPropagator, w3c trace context,
+// baggage and x-trace-id by default.
+//
+//	headers := otel.BytesMapCarrier{}
+//	otel.Inject(ctx, headers)
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	GetCarrierPropagator().Inject(ctx, carrier)
+}
+
+// Extract return the context with the remote span context of carrier, the w3c traceparent is preferred to x-trace-id.
+//
+//	ctx := otel.Extract(ctx, otel.TableCarrier(delivery.Headers))
+//	ctx, span := tracer.Start(ctx, "consume")
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return GetCarrierPropagator().Extract(ctx, carrier)
+}
+
+// MapCarrier map[string]string carrier
+type MapCarrier map[string]string
+
+// Get implements Get of propagation.TextMapCarrier
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+// Set implements Set of propagation.TextMapCarrier
+func (c MapCarrier) Set(key, val string) {
+	c[key] = val
+}
+
+// Keys implements Keys of propagation.TextMapCarrier
+func (c MapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// BytesMapCarrier map[string][]byte carrier
+type BytesMapCarrier map[string][]byte
+
+// Get implements Get of propagation.TextMapCarrier
+func (c BytesMapCarrier) Get(key string) string {
+	return string(c[key])
+}
+
+// Set implements Set of propagation.TextMapCarrier
+func (c BytesMapCarrier) Set(key, val string) {
+	c[key] = []byte(val)
+}
+
+// Keys implements Keys of propagation.TextMapCarrier
+func (c BytesMapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Header the key/value header of message, like sarama.RecordHeader, convert by Header(h).
+type Header struct {
+	Key   []byte
+	Value []byte
+}
+
+// HeadersCarrier []Header carrier, the header of same key is replaced by Set.
+type HeadersCarrier []Header
+
+// Get implements Get of propagation.TextMapCarrier
+func (c *HeadersCarrier) Get(key string) string {
+	for _, header := range *c {
+		if string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Set implements Set of propagation.TextMapCarrier
+func (c *HeadersCarrier) Set(key, val string) {
+	for i, header := range *c {
+		if string(header.Key) == key {
+			(*c)[i].Value = []byte(val)
+			return
+		}
+	}
+	*c = append(*c, Header{Key: []byte(key), Value: []byte(val)})
+}
+
+// Keys implements Keys of propagation.TextMapCarrier
+func (c *HeadersCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c))
+	for _, header := range *c {
+		keys = append(keys, string(header.Key))
+	}
+	return keys
+}
+
+// TableCarrier AMQP-style table carrier, like amqp.Table, convert by TableCarrier(table).
+// the value is set as string, the value of string and []byte can be read.
+type TableCarrier map[string]interface{}
+
+// Get implements Get of propagation.TextMapCarrier
+func (c TableCarrier) Get(key string) string {
+	switch v := c[key].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+// Set implements Set of propagation.TextMapCarrier
+func (c TableCarrier) Set(key, val string) {
+	c[key] = val
+}
+
+// Keys implements Keys of propagation.TextMapCarrier
+func (c TableCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
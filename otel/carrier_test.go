@@ -0,0 +1,51 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCarrier(t *testing.T) {
+	newTestRecorder()
+	ctx, span := otel.Tracer("test").Start(context.Background(), "produce")
+	defer span.End()
+
+	carriers := []propagation.TextMapCarrier{MapCarrier{}, BytesMapCarrier{}, &HeadersCarrier{}, TableCarrier{}}
+	for _, carrier := range carriers {
+		Inject(ctx, carrier)
+		assert.ElementsMatch(t, []string{"traceparent", "x-trace-id"}, carrier.Keys())
+
+		sc := trace.SpanContextFromContext(Extract(context.Background(), carrier))
+		assert.Equal(t, span.SpanContext().TraceID(), sc.TraceID())
+		assert.True(t, sc.IsRemote())
+	}
+
+	// x-trace-id only, like the message produced by jaeger.
+	sc := trace.SpanContextFromContext(Extract(context.Background(), TableCarrier{"x-trace-id": []byte("f:a:0:1")}))
+	assert.Equal(t, "0000000000000000000000000000000f", sc.TraceID().String())
+}
+
+func TestCarrierSetPropagator(t *testing.T) {
+	newTestRecorder()
+	ctx, span := otel.Tracer("test").Start(context.Background(), "produce")
+	defer span.End()
+
+	// the carriers follow the propagator set after init, with x-trace-id.
+	defer SetPropagator(GetPropagator())
+	SetPropagator(propagation.Baggage{})
+
+	carrier := MapCarrier{}
+	Inject(ctx, carrier)
+	assert.Equal(t, []string{"x-trace-id"}, carrier.Keys())
+}
+
+func TestDefaultPropagator(t *testing.T) {
+	// x-trace-id is only sent by the carriers, not by the global propagator.
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, GetPropagator().Fields())
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage", "x-trace-id"}, GetCarrierPropagator().Fields())
+}
@@ -0,0 +1,149 @@
+// Package gormtrace trace gorm by opentelemetry, the spans have table, rows affected and statement attributes.
+package gormtrace
+
+import (
+	"errors"
+
+	"github.com/rfyiamcool/go-tracer/internal/sqlhook"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracerName = "github.com/rfyiamcool/go-tracer/otel/gormtrace"
+	spanKey    = "go-tracer:span"
+)
+
+type Plugin struct {
+	tracer trace.Tracer
+
+	vars  bool
+	attrs []attribute.KeyValue
+}
+
+var _ gorm.Plugin = &Plugin{}
+
+type Option func(*Plugin)
+
+// WithTracerProvider default: the global tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(p *Plugin) {
+		p.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// WithVars add the statement with variables to db.statement, the variables are redacted by default.
+func WithVars() Option {
+	return func(p *Plugin) {
+		p.vars = true
+	}
+}
+
+// WithSystem set db.system attribute, like semconv.DBSystemMySQL. default: other_sql
+func WithSystem(system attribute.KeyValue) Option {
+	return func(p *Plugin) {
+		p.attrs[0] = system
+	}
+}
+
+// WithName add db.name attribute by the database name.
+func WithName(name string) Option {
+	return func(p *Plugin) {
+		p.attrs = append(p.attrs, semconv.DBNameKey.String(name))
+	}
+}
+
+// NewPlugin creates a new gorm plugin which collect spans,
+//
+//	db, err := gorm.Open(mysql.Open(dsn))
+//	db.Use(gormtrace.NewPlugin(gormtrace.WithSystem(semconv.DBSystemMySQL)))
+//	db.WithContext(ctx).First(&user)
+func NewPlugin(opts ...Option) *Plugin {
+	p := &Plugin{
+		tracer: otel.GetTracerProvider().Tracer(tracerName),
+		attrs:  []attribute.KeyValue{semconv.DBSystemOtherSQL},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *Plugin) Name() string {
+	return "go-tracer:otel-gorm"
+}
+
+// Initialize register the before and after callbacks of create, query, update, delete, row and raw.
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	callbacks := []struct {
+		before, after registerer
+		op            string
+	}{
+		{cb.Create().Before("gorm:create"), cb.Create().After("gorm:create"), "create"},
+		{cb.Query().Before("gorm:query"), cb.Query().After("gorm:query"), "query"},
+		{cb.Update().Before("gorm:update"), cb.Update().After("gorm:update"), "update"},
+		{cb.Delete().Before("gorm:delete"), cb.Delete().After("gorm:delete"), "delete"},
+		{cb.Row().Before("gorm:row"), cb.Row().After("gorm:row"), "row"},
+		{cb.Raw().Before("gorm:raw"), cb.Raw().After("gorm:raw"), "raw"},
+	}
+
+	for _, c := range callbacks {
+		if err := c.before.Register("go-tracer:otel_before_"+c.op, p.before("gorm."+c.op, c.op)); err != nil {
+			return err
+		}
+		if err := c.after.Register("go-tracer:otel_after_"+c.op, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerer is the callback of gorm processor.
+type registerer interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+func (p *Plugin) before(name, operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := p.tracer.Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(p.attrs...),
+			trace.WithAttributes(semconv.DBOperationKey.String(operation)),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *Plugin) after(db *gorm.DB) {
+	val, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := val.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBSQLTableKey.String(db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		semconv.DBStatementKey.String(p.statement(db)),
+	)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
+
+func (p *Plugin) statement(db *gorm.DB) string {
+	query := db.Statement.SQL.String()
+	if p.vars {
+		return db.Dialector.Explain(query, db.Statement.Vars...)
+	}
+	return sqlhook.Redact(query)
+}
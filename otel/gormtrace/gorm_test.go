@@ -0,0 +1,55 @@
+package gormtrace
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/rfyiamcool/go-tracer/internal/sqlhook/sqltest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type user struct {
+	ID   int64
+	Name string
+}
+
+func TestPlugin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	sql.Register("sqltest-otel-gormtrace", &sqltest.Driver{})
+	sqlDB, err := sql.Open("sqltest-otel-gormtrace", "")
+	assert.Nil(t, err)
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}))
+	assert.Nil(t, err)
+	assert.Nil(t, db.Use(NewPlugin(WithTracerProvider(provider), WithSystem(semconv.DBSystemMySQL), WithVars())))
+
+	ctx := context.Background()
+	err = db.WithContext(ctx).Model(&user{ID: 1}).Update("name", "bob").Error
+	assert.Nil(t, err)
+	err = db.WithContext(ctx).First(&user{}, 1).Error
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	err = db.WithContext(ctx).Exec("delete from error").Error
+	assert.Equal(t, sqltest.ErrFake, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+
+	update, query, raw := spans[0], spans[1], spans[2]
+	assert.Equal(t, "gorm.update", update.Name())
+	assert.Contains(t, update.Attributes(), semconv.DBSystemMySQL)
+	assert.Contains(t, update.Attributes(), semconv.DBSQLTableKey.String("users"))
+	assert.Contains(t, update.Attributes(), semconv.DBStatementKey.String("UPDATE `users` SET `name`='bob' WHERE `id` = 1"))
+
+	assert.Equal(t, codes.Unset, query.Status().Code)
+	assert.Equal(t, codes.Error, raw.Status().Code)
+}
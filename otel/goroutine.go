@@ -0,0 +1,83 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type goConfig struct {
+	detached bool
+}
+
+// GoOption specifies goroutine launcher configuration options.
+type GoOption interface {
+	apply(*goConfig)
+}
+
+type goOptionFunc func(*goConfig)
+
+func (o goOptionFunc) apply(c *goConfig) {
+	o(c)
+}
+
+// WithGoDetached the span is a new root span which links to the span of ctx, and the context of fn
+// is not canceled with ctx, for the work which outlives the caller, like async notify.
+func WithGoDetached() GoOption {
+	return goOptionFunc(func(cfg *goConfig) {
+		cfg.detached = true
+	})
+}
+
+// Go run fn in a new goroutine with the child span of ctx, the span is ended when fn returns,
+// the error and panic of fn are recorded to the span, the panic is recovered.
+//
+//	otel.Go(ctx, "send-email", func(ctx context.Context) error {
+//		return sendEmail(ctx, user)
+//	}, otel.WithGoDetached())
+func Go(ctx context.Context, name string, fn func(ctx context.Context) error, opts ...GoOption) {
+	cfg := &goConfig{}
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+
+	var sopts []trace.SpanStartOption
+	if cfg.detached {
+		ctx = context.WithoutCancel(ctx)
+		sopts = append(sopts, trace.WithNewRoot())
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			sopts = append(sopts, trace.WithLinks(trace.Link{SpanContext: sc}))
+		}
+	}
+
+	ctx, span := otel.GetTracerProvider().Tracer("").Start(ctx, name, sopts...)
+	go runWithSpan(ctx, span, fn)
+}
+
+// runWithSpan run fn, record the error and panic of fn, then end span.
+func runWithSpan(ctx context.Context, span trace.Span, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(
+				semconv.ExceptionTypeKey.String("panic"),
+				semconv.ExceptionMessageKey.String(fmt.Sprint(r)),
+				semconv.ExceptionStacktraceKey.String(string(debug.Stack())),
+			))
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	err = fn(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestGo(t *testing.T) {
+	recorder := newTestRecorder()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	ctx, cancel := context.WithCancel(ctx)
+
+	done := make(chan error, 3)
+	Go(ctx, "child", func(ctx context.Context) error {
+		done <- nil
+		return errors.New("failed")
+	})
+	Go(ctx, "panic", func(ctx context.Context) error {
+		defer func() { done <- nil }()
+		panic("boom")
+	})
+	Go(ctx, "detached", func(ctx context.Context) error {
+		<-time.After(10 * time.Millisecond)
+		done <- ctx.Err()
+		return nil
+	}, WithGoDetached())
+	cancel()
+	parent.End()
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, <-done)
+	}
+	assert.Eventually(t, func() bool {
+		return len(recorder.Ended()) == 4
+	}, time.Second, 10*time.Millisecond)
+
+	spans := make(map[string]tracesdk.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	parentSC := parent.SpanContext()
+	assert.Equal(t, parentSC.SpanID(), spans["child"].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans["child"].Status().Code)
+
+	assert.Equal(t, codes.Error, spans["panic"].Status().Code)
+	assert.Equal(t, "exception", spans["panic"].Events()[0].Name)
+
+	detached := spans["detached"]
+	assert.False(t, detached.Parent().IsValid())
+	assert.NotEqual(t, parentSC.TraceID(), detached.SpanContext().TraceID())
+	assert.Equal(t, parentSC.SpanID(), detached.Links()[0].SpanContext.SpanID())
+}
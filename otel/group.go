@@ -0,0 +1,129 @@
+package otel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rfyiamcool/go-tracer/internal/taskgroup"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type groupConfig struct {
+	limit int
+}
+
+// GroupOption specifies traced errgroup configuration options.
+type GroupOption interface {
+	apply(*groupConfig)
+}
+
+type groupOptionFunc func(*groupConfig)
+
+func (o groupOptionFunc) apply(c *groupConfig) {
+	o(c)
+}
+
+// WithGroupLimit run at most n tasks concurrently like a worker pool, default: no limit.
+func WithGroupLimit(n int) GroupOption {
+	return groupOptionFunc(func(cfg *groupConfig) {
+		cfg.limit = n
+	})
+}
+
+// Group is a traced errgroup, each task runs with a child span of the group span,
+// the first error cancels the other tasks and is recorded on the group span.
+type Group struct {
+	group  *taskgroup.Group
+	tracer trace.Tracer
+	span   trace.Span
+	name   string
+	limit  int
+
+	mu    sync.Mutex
+	tasks int
+	once  sync.Once
+}
+
+// NewGroup start the group span, return the group and the context canceled by the first error.
+//
+//	g, ctx := otel.NewGroup(ctx, "load-users", otel.WithGroupLimit(8))
+//	for _, id := range ids {
+//		id := id
+//		g.Go(id, func(ctx context.Context) error {
+//			return loadUser(ctx, id)
+//		})
+//	}
+//	err := g.Wait()
+func NewGroup(ctx context.Context, name string, opts ...GroupOption) (*Group, context.Context) {
+	cfg := &groupConfig{}
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+
+	tracer := otel.GetTracerProvider().Tracer("")
+	ctx, span := tracer.Start(ctx, name)
+	g := &Group{
+		group:  taskgroup.New(ctx, cfg.limit),
+		tracer: tracer,
+		span:   span,
+		name:   name,
+		limit:  cfg.limit,
+	}
+	return g, g.group.Context()
+}
+
+// Go run fn in a new goroutine with the task span which has index and key attributes, the time waiting
+// for the free worker is the group.task.queue_wait_ms attribute.
+func (g *Group) Go(key string, fn func(ctx context.Context) error) {
+	g.mu.Lock()
+	g.tasks++
+	g.mu.Unlock()
+
+	g.group.Go(key, func(ctx context.Context, task *taskgroup.Task) error {
+		// the span starts when the worker is acquired, the wait is the group.task.queue_wait_ms attribute.
+		ctx, span := g.tracer.Start(ctx, g.name+".task")
+		span.SetAttributes(
+			attribute.Int("group.task.index", task.Index),
+			attribute.Int64("group.task.queue_wait_ms", task.QueueWait.Milliseconds()),
+		)
+		if task.Key != "" {
+			span.SetAttributes(attribute.String("group.task.key", task.Key))
+		}
+		if task.Saturated {
+			span.SetAttributes(attribute.Bool("group.pool.saturated", true))
+		}
+		if task.Canceled {
+			span.SetAttributes(attribute.Bool("group.task.canceled", true))
+			span.End()
+			return nil
+		}
+
+		return runWithSpan(ctx, span, fn)
+	})
+}
+
+// Wait blocks until all tasks return, then end the group span and return the first error.
+func (g *Group) Wait() error {
+	err := g.group.Wait()
+	g.once.Do(func() {
+		g.mu.Lock()
+		g.span.SetAttributes(attribute.Int("group.tasks", g.tasks))
+		g.mu.Unlock()
+
+		if g.limit > 0 {
+			g.span.SetAttributes(
+				attribute.Int("group.pool.size", g.limit),
+				attribute.Bool("group.pool.saturated", g.group.Saturated()),
+			)
+		}
+		if err != nil {
+			g.span.RecordError(err)
+			g.span.SetStatus(codes.Error, err.Error())
+		}
+		g.span.End()
+	})
+	return err
+}
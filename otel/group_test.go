@@ -0,0 +1,91 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestGroup(t *testing.T) {
+	recorder := newTestRecorder()
+
+	g, ctx := NewGroup(context.Background(), "load", WithGroupLimit(1))
+
+	started := make(chan struct{})
+	failed := errors.New("failed")
+	g.Go("first", func(ctx context.Context) error {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		return failed
+	})
+	<-started
+	for i := 0; i < 2; i++ {
+		g.Go(strconv.Itoa(i), func(ctx context.Context) error {
+			return nil
+		})
+	}
+
+	assert.Equal(t, failed, g.Wait())
+	assert.NotNil(t, ctx.Err())
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 4)
+
+	group := spans[3]
+	assert.Equal(t, "load", group.Name())
+	assert.Equal(t, codes.Error, group.Status().Code)
+	assert.Contains(t, group.Attributes(), attribute.Int("group.tasks", 3))
+	assert.Contains(t, group.Attributes(), attribute.Bool("group.pool.saturated", true))
+
+	for _, span := range spans[:3] {
+		assert.Equal(t, "load.task", span.Name())
+		assert.Equal(t, group.SpanContext().SpanID(), span.Parent().SpanID())
+
+		if contains(span.Attributes(), attribute.String("group.task.key", "first")) {
+			assert.Equal(t, codes.Error, span.Status().Code)
+			continue
+		}
+		assert.Contains(t, span.Attributes(), attribute.Bool("group.task.canceled", true))
+		assert.Contains(t, span.Attributes(), attribute.Bool("group.pool.saturated", true))
+	}
+}
+
+func contains(attrs []attribute.KeyValue, kv attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if attr == kv {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGroupQueueWait(t *testing.T) {
+	recorder := newTestRecorder()
+
+	g, _ := NewGroup(context.Background(), "load", WithGroupLimit(1))
+	for i := 0; i < 2; i++ {
+		g.Go(strconv.Itoa(i), func(ctx context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+	}
+	assert.Nil(t, g.Wait())
+
+	// the waiting task starts after the running one ends.
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	first, second := spans[0], spans[1]
+	assert.False(t, second.StartTime().Before(first.EndTime()))
+	assert.Less(t, second.EndTime().Sub(second.StartTime()), 40*time.Millisecond)
+	for _, attr := range second.Attributes() {
+		if attr.Key == "group.task.queue_wait_ms" {
+			assert.GreaterOrEqual(t, attr.Value.AsInt64(), int64(10))
+		}
+	}
+}
@@ -2,51 +2,347 @@ package otel
 
 import (
 	"context"
+	"sync"
 
+	"github.com/rfyiamcool/go-tracer/internal/grpcutil"
 	grpcotel "go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
-// StreamClientInterceptor for grpc
-func StreamClientInterceptor() grpc.StreamClientInterceptor {
-	return grpcotel.StreamClientInterceptor()
+type grpcConfig struct {
+	filter         grpcutil.MethodFilter
+	tracerProvider trace.TracerProvider
+
+	errorDetails bool
+
+	payload        bool
+	payloadMaxSize int
+	payloadMasks   []string
+
+	spanNameFunc func(info *grpc.UnaryServerInfo) string
+	tagsFunc     func(ctx context.Context, info *grpc.UnaryServerInfo, req interface{}) []attribute.KeyValue
+}
+
+// GrpcOption specifies grpc interceptor configuration options.
+type GrpcOption interface {
+	apply(*grpcConfig)
+}
+
+type grpcOptionFunc func(*grpcConfig)
+
+func (o grpcOptionFunc) apply(c *grpcConfig) {
+	o(c)
+}
+
+func newGrpcConfig(opts []GrpcOption) *grpcConfig {
+	cfg := &grpcConfig{}
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+	return cfg
+}
+
+// WithGrpcIncludeMethods only trace the methods matched by glob patterns, like /user.User/*
+func WithGrpcIncludeMethods(patterns ...string) GrpcOption {
+	return grpcOptionFunc(func(cfg *grpcConfig) {
+		cfg.filter.Include(patterns...)
+	})
+}
+
+// WithGrpcExcludeMethods skip the methods matched by glob patterns, like /grpc.health.v1.Health/*
+func WithGrpcExcludeMethods(patterns ...string) GrpcOption {
+	return grpcOptionFunc(func(cfg *grpcConfig) {
+		cfg.filter.Exclude(patterns...)
+	})
+}
+
+// WithGrpcTracerProvider default: the global tracer provider.
+func WithGrpcTracerProvider(provider trace.TracerProvider) GrpcOption {
+	return grpcOptionFunc(func(cfg *grpcConfig) {
+		cfg.tracerProvider = provider
+	})
+}
+
+// WithGrpcErrorDetails attach errdetails.RequestInfo with trace id to the status error returned by server,
+// the client can get trace id by GrpcTraceIDFromError.
+func WithGrpcErrorDetails() GrpcOption {
+	return grpcOptionFunc(func(cfg *grpcConfig) {
+		cfg.errorDetails = true
+	})
+}
+
+// WithGrpcPayload add request and response message by protojson to span event, the fields of masks are masked,
+// like `password`, `user.token`. the payload is truncated to maxSize when maxSize > 0.
+func WithGrpcPayload(maxSize int, masks ...string) GrpcOption {
+	return grpcOptionFunc(func(cfg *grpcConfig) {
+		cfg.payload = true
+		cfg.payloadMaxSize = maxSize
+		cfg.payloadMasks = masks
+	})
 }
 
-// StreamServerInterceptor for grpc
-func StreamServerInterceptor() grpc.StreamServerInterceptor {
-	return grpcotel.StreamServerInterceptor()
+// WithGrpcSpanName custom the name of server span, default: info.FullMethod without the leading slash.
+func WithGrpcSpanName(fn func(info *grpc.UnaryServerInfo) string) GrpcOption {
+	return grpcOptionFunc(func(cfg *grpcConfig) {
+		cfg.spanNameFunc = fn
+	})
+}
+
+// WithGrpcAttributes add custom attributes to server span.
+func WithGrpcAttributes(fn func(ctx context.Context, info *grpc.UnaryServerInfo, req interface{}) []attribute.KeyValue) GrpcOption {
+	return grpcOptionFunc(func(cfg *grpcConfig) {
+		cfg.tagsFunc = fn
+	})
+}
+
+// tracer returns the tracer of the provider, the global provider is resolved at call time.
+func (cfg *grpcConfig) tracer(name string) trace.Tracer {
+	provider := cfg.tracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(name)
+}
+
+// grpcotelOptions returns the options of otelgrpc interceptors.
+func (cfg *grpcConfig) grpcotelOptions() []grpcotel.Option {
+	if cfg.tracerProvider == nil {
+		return nil
+	}
+	return []grpcotel.Option{grpcotel.WithTracerProvider(cfg.tracerProvider)}
+}
+
+func (cfg *grpcConfig) addPayloadEvent(span trace.Span, name string, msg interface{}) {
+	if !cfg.payload {
+		return
+	}
+	span.AddEvent(name, trace.WithAttributes(
+		attribute.String("message.payload", grpcutil.MarshalPayload(msg, cfg.payloadMaxSize, cfg.payloadMasks)),
+	))
+}
+
+func (cfg *grpcConfig) withErrorDetails(ctx context.Context, err error) error {
+	if !cfg.errorDetails || err == nil {
+		return err
+	}
+
+	traceID, spanID := GetTraceSpanIDsFromCtx(ctx)
+	return grpcutil.WithTraceDetails(err, traceID, spanID)
+}
+
+// StreamClientInterceptor for grpc, the trace-id and span-id of server are recorded like
+// UnaryClientInterceptor when the first message or error is received.
+func StreamClientInterceptor(opts ...GrpcOption) grpc.StreamClientInterceptor {
+	cfg := newGrpcConfig(opts)
+	interceptor := grpcotel.StreamClientInterceptor(cfg.grpcotelOptions()...)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		if !cfg.filter.Allow(method) {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		// the attempts of call are recorded by GrpcAttemptHandler.
+		ctx = grpcutil.ContextWithCallState(ctx)
+		return interceptor(ctx, desc, cc, method,
+			func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				cs, err := streamer(ctx, desc, cc, method, opts...)
+				if err != nil {
+					return cs, err
+				}
+				return &traceHeaderClientStream{ClientStream: cs, ctx: ctx}, nil
+			},
+			opts...,
+		)
+	}
+}
+
+// traceHeaderClientStream record the trace header of server before the span is ended.
+type traceHeaderClientStream struct {
+	grpc.ClientStream
+
+	ctx  context.Context
+	once sync.Once
+}
+
+func (cs *traceHeaderClientStream) RecvMsg(m interface{}) error {
+	err := cs.ClientStream.RecvMsg(m)
+	cs.once.Do(func() {
+		// the header is received with the first message or the status.
+		if header, herr := cs.ClientStream.Header(); herr == nil {
+			GrpcRecordHeader(cs.ctx, header)
+		}
+	})
+	return err
+}
+
+// StreamServerInterceptor for grpc, trace-id and span-id are sent by header and trailer.
+func StreamServerInterceptor(opts ...GrpcOption) grpc.StreamServerInterceptor {
+	cfg := newGrpcConfig(opts)
+	interceptor := grpcotel.StreamServerInterceptor(cfg.grpcotelOptions()...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !cfg.filter.Allow(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		return interceptor(srv, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+			header := grpcTraceHeader(stream.Context())
+			stream.SetHeader(header) // send with the first message.
+
+			ctx := context.WithValue(stream.Context(), traceHeaderKey{}, true)
+			err := handler(srv, &traceHeaderStream{ServerStream: stream, ctx: ctx})
+			stream.SetTrailer(header)
+			return cfg.withErrorDetails(ctx, err)
+		})
+	}
+}
+
+// traceHeaderStream mark the trace header is set in the context.
+type traceHeaderStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *traceHeaderStream) Context() context.Context {
+	return s.ctx
 }
 
 // UnaryClientInterceptor for grpc
-func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
-	return grpcotel.UnaryClientInterceptor()
+func UnaryClientInterceptor(opts ...GrpcOption) grpc.UnaryClientInterceptor {
+	cfg := newGrpcConfig(opts)
+	interceptor := grpcotel.UnaryClientInterceptor(cfg.grpcotelOptions()...)
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		if !cfg.filter.Allow(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		// the attempts of call are recorded by GrpcAttemptHandler.
+		ctx = grpcutil.ContextWithCallState(ctx)
+		return interceptor(ctx, method, req, reply, cc,
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				span := trace.SpanFromContext(ctx)
+				cfg.addPayloadEvent(span, "grpc.request", req)
+
+				var header metadata.MD
+				err := invoker(ctx, method, req, reply, cc, append(opts[:len(opts):len(opts)], grpc.Header(&header))...)
+				if err == nil {
+					cfg.addPayloadEvent(span, "grpc.response", reply)
+				}
+				GrpcRecordHeader(ctx, header)
+				return err
+			},
+			opts...,
+		)
+	}
 }
 
-// UnaryServerInterceptor for grpc
-func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
-	return grpcotel.UnaryServerInterceptor()
+// UnaryServerInterceptor for grpc, trace-id and span-id are sent by header.
+func UnaryServerInterceptor(opts ...GrpcOption) grpc.UnaryServerInterceptor {
+	cfg := newGrpcConfig(opts)
+	interceptor := grpcotel.UnaryServerInterceptor(cfg.grpcotelOptions()...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !cfg.filter.Allow(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			span := trace.SpanFromContext(ctx)
+			if cfg.spanNameFunc != nil {
+				span.SetName(cfg.spanNameFunc(info))
+			}
+			if cfg.tagsFunc != nil {
+				span.SetAttributes(cfg.tagsFunc(ctx, info, req)...)
+			}
+			cfg.addPayloadEvent(span, "grpc.request", req)
+
+			// merged with the header of handler, send with the response or by handler.
+			grpc.SetHeader(ctx, grpcTraceHeader(ctx))
+			ctx = context.WithValue(ctx, traceHeaderKey{}, true)
+
+			resp, err := handler(ctx, req)
+			if err == nil {
+				cfg.addPayloadEvent(span, "grpc.response", resp)
+			}
+			return resp, cfg.withErrorDetails(ctx, err)
+		})
+	}
 }
 
 // GrpcDialOption grpc client option
-func GrpcUnaryDialOption() grpc.DialOption {
-	return grpc.WithUnaryInterceptor(UnaryClientInterceptor())
+func GrpcUnaryDialOption(opts ...GrpcOption) grpc.DialOption {
+	return grpc.WithUnaryInterceptor(UnaryClientInterceptor(opts...))
 }
 
 // GrpcUnaryServerOption grpc server option
-func GrpcUnaryServerOption() grpc.ServerOption {
-	return grpc.UnaryInterceptor(UnaryServerInterceptor())
+func GrpcUnaryServerOption(opts ...GrpcOption) grpc.ServerOption {
+	return grpc.UnaryInterceptor(UnaryServerInterceptor(opts...))
 }
 
+// traceHeaderKey the trace header has been set by server interceptor.
+type traceHeaderKey struct{}
+
 // GrpcSendHeader insert traceID and spanID to header, grpc send header
 func GrpcSendHeader(ctx context.Context, header metadata.MD) {
 	if header == nil {
 		header = metadata.Pairs()
 	}
 
-	spctx := SpanContextFromContext(ctx)
-	header.Append(HeaderTraceID, spctx.TraceID().String())
-	header.Append(HeaderSpanID, spctx.SpanID().String())
+	if ctx.Value(traceHeaderKey{}) == nil {
+		header = metadata.Join(header, grpcTraceHeader(ctx))
+	}
 
 	grpc.SendHeader(ctx, header)
 }
+
+func grpcTraceHeader(ctx context.Context) metadata.MD {
+	spctx := SpanContextFromContext(ctx)
+	return metadata.Pairs(
+		HeaderTraceID, spctx.TraceID().String(),
+		HeaderSpanID, spctx.SpanID().String(),
+	)
+}
+
+// GrpcTraceIDFromError get the trace id of server from the status error, the server should enable WithGrpcErrorDetails.
+func GrpcTraceIDFromError(err error) string {
+	if err == nil {
+		return ""
+	}
+	return grpcutil.TraceIDFromStatus(status.Convert(err))
+}
+
+// GrpcTraceIDsFromHeader get the trace-id and span-id of server from response header or trailer.
+func GrpcTraceIDsFromHeader(md metadata.MD) (string, string) {
+	var traceID, spanID string
+	if vals := md.Get(HeaderTraceID); len(vals) > 0 {
+		traceID = vals[0]
+	}
+	if vals := md.Get(HeaderSpanID); len(vals) > 0 {
+		spanID = vals[0]
+	}
+	return traceID, spanID
+}
+
+// GrpcRecordHeader add the trace-id and span-id of server to the span of context.
+//
+//	var header metadata.MD
+//	resp, err := client.GetUserInfo(ctx, req, grpc.Header(&header))
+//	otel.GrpcRecordHeader(ctx, header)
+func GrpcRecordHeader(ctx context.Context, md metadata.MD) {
+	traceID, spanID := GrpcTraceIDsFromHeader(md)
+	if traceID == "" {
+		return
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("grpc.server.trace_id", traceID),
+		attribute.String("grpc.server.span_id", spanID),
+	)
+}
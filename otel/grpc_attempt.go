@@ -0,0 +1,89 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/rfyiamcool/go-tracer/internal/grpcutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+const grpcAttemptTracerName = "github.com/rfyiamcool/go-tracer/otel/grpc-attempt"
+
+type attemptSpanKey struct{}
+
+// GrpcAttemptHandler grpc client stats.Handler, record each attempt of call as the child span of
+// UnaryClientInterceptor span, so the retries of grpc retry policy are visible.
+type GrpcAttemptHandler struct {
+	cfg *grpcConfig
+}
+
+var _ stats.Handler = &GrpcAttemptHandler{}
+
+// NewGrpcAttemptHandler
+func NewGrpcAttemptHandler(opts ...GrpcOption) *GrpcAttemptHandler {
+	return &GrpcAttemptHandler{
+		cfg: newGrpcConfig(opts),
+	}
+}
+
+// GrpcAttemptDialOption grpc client option, should be used with GrpcUnaryDialOption or StreamClientInterceptor.
+func GrpcAttemptDialOption(opts ...GrpcOption) grpc.DialOption {
+	return grpc.WithStatsHandler(NewGrpcAttemptHandler(opts...))
+}
+
+// TagRPC start attempt span, it's called by grpc before each attempt.
+func (h *GrpcAttemptHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	if !h.cfg.filter.Allow(info.FullMethodName) || !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	_, span := h.cfg.tracer(grpcAttemptTracerName).Start(ctx, info.FullMethodName+"/attempt")
+	return context.WithValue(ctx, attemptSpanKey{}, span)
+}
+
+// HandleRPC add the attempt number, backoff and status of attempt to span.
+func (h *GrpcAttemptHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
+	span, ok := ctx.Value(attemptSpanKey{}).(trace.Span)
+	if !ok || !rs.IsClient() {
+		return
+	}
+
+	state := grpcutil.CallStateFromContext(ctx)
+	switch rs := rs.(type) {
+	case *stats.Begin:
+		span.SetAttributes(attribute.Bool("grpc.attempt.transparent_retry", rs.IsTransparentRetryAttempt))
+		if state != nil {
+			attempt, backoff := state.Begin(rs.BeginTime)
+			span.SetAttributes(
+				attribute.Int("grpc.attempt", attempt),
+				attribute.Int64("grpc.attempt.backoff_ms", backoff.Milliseconds()),
+			)
+		}
+
+	case *stats.End:
+		if state != nil {
+			state.End(rs.EndTime)
+		}
+
+		st := status.Convert(rs.Error)
+		span.SetAttributes(attribute.Int64("rpc.grpc.status_code", int64(st.Code())))
+		if st.Code() != grpccodes.OK {
+			span.SetStatus(codes.Error, st.Message())
+		}
+		span.End(trace.WithTimestamp(rs.EndTime))
+	}
+}
+
+// TagConn implements stats.Handler
+func (h *GrpcAttemptHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler
+func (h *GrpcAttemptHandler) HandleConn(ctx context.Context, cs stats.ConnStats) {}
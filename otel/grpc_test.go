@@ -0,0 +1,290 @@
+package otel
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.Service == "unknown" {
+		return nil, status.Error(grpccodes.NotFound, "unknown service")
+	}
+	GrpcSendHeader(ctx, metadata.Pairs("biz", "val"))
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func newTestRecorder() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func newHealthConn(t *testing.T, sopts []grpc.ServerOption, dopts ...grpc.DialOption) (*grpc.ClientConn, func()) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(sopts...)
+	healthpb.RegisterHealthServer(server, &healthServer{})
+	go server.Serve(listener)
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}
+	dopts = append(dopts, grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	conn, err := grpc.Dial("bufnet", dopts...)
+	assert.Nil(t, err)
+
+	return conn, func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestGrpcTraceHeader(t *testing.T) {
+	recorder := newTestRecorder()
+
+	conn, stop := newHealthConn(t,
+		[]grpc.ServerOption{GrpcUnaryServerOption()},
+		GrpcUnaryDialOption(),
+	)
+	defer stop()
+
+	var header metadata.MD
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"val"}, header.Get("biz"))
+	assert.Len(t, header.Get(HeaderTraceID), 1)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	server, client := spans[0], spans[1]
+	assert.Equal(t, server.SpanContext().SpanID().String(), header.Get(HeaderSpanID)[0])
+
+	var recorded bool
+	for _, attr := range client.Attributes() {
+		if attr.Key == "grpc.server.span_id" {
+			recorded = true
+			assert.Equal(t, server.SpanContext().SpanID().String(), attr.Value.AsString())
+		}
+	}
+	assert.True(t, recorded)
+}
+
+func TestGrpcStreamTraceHeader(t *testing.T) {
+	recorder := newTestRecorder()
+
+	conn, stop := newHealthConn(t,
+		[]grpc.ServerOption{grpc.StreamInterceptor(StreamServerInterceptor())},
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	defer stop()
+
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	header, err := stream.Header()
+	assert.Nil(t, err)
+	trailer := stream.Trailer()
+	assert.Len(t, header.Get(HeaderTraceID), 1)
+	assert.Equal(t, header.Get(HeaderTraceID), trailer.Get(HeaderTraceID))
+	assert.Equal(t, header.Get(HeaderSpanID), trailer.Get(HeaderSpanID))
+
+	assert.Eventually(t, func() bool {
+		return len(recorder.Ended()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	var server, client tracesdk.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.SpanKind() == trace.SpanKindServer {
+			server = span
+		} else {
+			client = span
+		}
+	}
+	assert.Equal(t, server.SpanContext().TraceID().String(), header.Get(HeaderTraceID)[0])
+	assert.Equal(t, server.SpanContext().SpanID().String(), header.Get(HeaderSpanID)[0])
+	assert.Contains(t, client.Attributes(), attribute.String("grpc.server.span_id", header.Get(HeaderSpanID)[0]))
+}
+
+func TestGrpcAttemptHandler(t *testing.T) {
+	global := newTestRecorder()
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	serviceConfig := `{"methodConfig": [{
+		"name": [{"service": "grpc.health.v1.Health"}],
+		"retryPolicy": {
+			"maxAttempts": 3,
+			"initialBackoff": "0.01s",
+			"maxBackoff": "0.01s",
+			"backoffMultiplier": 1,
+			"retryableStatusCodes": ["NOT_FOUND"]
+		}
+	}]}`
+
+	conn, stop := newHealthConn(t, nil,
+		GrpcUnaryDialOption(WithGrpcTracerProvider(provider)),
+		GrpcAttemptDialOption(WithGrpcTracerProvider(provider)),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	)
+	defer stop()
+
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, grpccodes.NotFound, status.Code(err))
+	assert.Empty(t, global.Ended())
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 4)
+
+	call := spans[3]
+	for i, span := range spans[:3] {
+		assert.Equal(t, "/grpc.health.v1.Health/Check/attempt", span.Name())
+		assert.Equal(t, call.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Contains(t, span.Attributes(), attribute.Int("grpc.attempt", i+1))
+		assert.Contains(t, span.Attributes(), attribute.Int64("rpc.grpc.status_code", int64(grpccodes.NotFound)))
+		assert.Equal(t, codes.Error, span.Status().Code)
+
+		var backoff bool
+		for _, attr := range span.Attributes() {
+			if attr.Key == "grpc.attempt.backoff_ms" {
+				backoff = true
+				if i == 0 {
+					assert.Equal(t, int64(0), attr.Value.AsInt64())
+				}
+			}
+		}
+		assert.True(t, backoff)
+	}
+}
+
+func TestGrpcMethodFilter(t *testing.T) {
+	recorder := newTestRecorder()
+
+	conn, stop := newHealthConn(t,
+		[]grpc.ServerOption{
+			GrpcUnaryServerOption(WithGrpcIncludeMethods("/grpc.health.v1.Health/*")),
+			grpc.StreamInterceptor(StreamServerInterceptor(WithGrpcExcludeMethods("*/Watch"))),
+		},
+		GrpcUnaryDialOption(WithGrpcExcludeMethods("*/Check")),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(WithGrpcIncludeMethods("/grpc.health.v1.Health/Watch"))),
+	)
+	defer stop()
+
+	// the client span of Check is excluded.
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "grpc.health.v1.Health/Check", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+
+	// the server span of Watch is excluded.
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	assert.Eventually(t, func() bool {
+		return len(recorder.Ended()) == 2
+	}, time.Second, 10*time.Millisecond)
+	spans = recorder.Ended()
+	assert.Equal(t, "grpc.health.v1.Health/Watch", spans[1].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[1].SpanKind())
+}
+
+// payloadEvents returns the payloads of grpc.request and grpc.response events.
+func payloadEvents(span tracesdk.ReadOnlySpan) map[string]string {
+	payloads := make(map[string]string)
+	for _, event := range span.Events() {
+		for _, attr := range event.Attributes {
+			if attr.Key == "message.payload" {
+				payloads[event.Name] = attr.Value.AsString()
+			}
+		}
+	}
+	return payloads
+}
+
+func TestGrpcPayload(t *testing.T) {
+	recorder := newTestRecorder()
+
+	conn, stop := newHealthConn(t,
+		[]grpc.ServerOption{GrpcUnaryServerOption(WithGrpcPayload(0, "service"))},
+		GrpcUnaryDialOption(WithGrpcPayload(0)),
+	)
+	defer stop()
+
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "secret"})
+	assert.Nil(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	// the service field is masked by server only.
+	server, client := payloadEvents(spans[0]), payloadEvents(spans[1])
+	assert.Equal(t, `{"service":"******"}`, server["grpc.request"])
+	assert.Equal(t, `{"service":"secret"}`, client["grpc.request"])
+	assert.Equal(t, `{"status":"SERVING"}`, server["grpc.response"])
+	assert.Equal(t, `{"status":"SERVING"}`, client["grpc.response"])
+}
+
+func TestGrpcErrorDetails(t *testing.T) {
+	recorder := newTestRecorder()
+
+	conn, stop := newHealthConn(t,
+		[]grpc.ServerOption{GrpcUnaryServerOption(WithGrpcErrorDetails(), WithGrpcPayload(0))},
+		GrpcUnaryDialOption(WithGrpcPayload(0)),
+	)
+	defer stop()
+
+	var header metadata.MD
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"}, grpc.Header(&header))
+	assert.Equal(t, grpccodes.NotFound, status.Code(err))
+	assert.NotEmpty(t, GrpcTraceIDFromError(err))
+	assert.Equal(t, header.Get(HeaderTraceID)[0], GrpcTraceIDFromError(err))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	server := spans[0]
+	assert.Equal(t, server.SpanContext().TraceID().String(), GrpcTraceIDFromError(err))
+	assert.Equal(t, codes.Error, server.Status().Code)
+
+	// the response isn't recorded on error.
+	for _, span := range spans {
+		payloads := payloadEvents(span)
+		assert.Equal(t, `{"service":"unknown"}`, payloads["grpc.request"])
+		assert.NotContains(t, payloads, "grpc.response")
+	}
+}
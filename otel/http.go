@@ -2,8 +2,10 @@ package otel
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rfyiamcool/go-tracer/internal/httputil"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -13,13 +15,17 @@ import (
 )
 
 const (
-	tracerKey  = "otel-go-contrib-tracer"
-	tracerName = "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	tracerKey      = "otel-go-contrib-tracer"
+	tracerName     = "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	httpTracerName = "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type config struct {
 	TracerProvider oteltrace.TracerProvider
 	Propagators    propagation.TextMapPropagator
+
+	clientTrace  bool
+	spanNameFunc func(r *http.Request) string
 }
 
 // Option specifies instrumentation configuration options.
@@ -49,7 +55,21 @@ func WithTracerProvider(provider oteltrace.TracerProvider) TracerOption {
 	})
 }
 
-func GinMiddleware(service string, opts ...TracerOption) gin.HandlerFunc {
+// WithClientTrace add the connection phase events of request to the client span, such as dns, connect and tls handshake.
+func WithClientTrace() TracerOption {
+	return tracerOptionFunc(func(cfg *config) {
+		cfg.clientTrace = true
+	})
+}
+
+// WithHttpSpanName custom the name of HttpMiddleware span, like the route of request, default: HTTP {method}.
+func WithHttpSpanName(fn func(r *http.Request) string) TracerOption {
+	return tracerOptionFunc(func(cfg *config) {
+		cfg.spanNameFunc = fn
+	})
+}
+
+func newConfig(opts []TracerOption) config {
 	cfg := config{}
 	for _, opt := range opts {
 		opt.apply(&cfg)
@@ -57,13 +77,18 @@ func GinMiddleware(service string, opts ...TracerOption) gin.HandlerFunc {
 	if cfg.TracerProvider == nil {
 		cfg.TracerProvider = otel.GetTracerProvider()
 	}
+	if cfg.Propagators == nil {
+		cfg.Propagators = otel.GetTextMapPropagator()
+	}
+	return cfg
+}
+
+func GinMiddleware(service string, opts ...TracerOption) gin.HandlerFunc {
+	cfg := newConfig(opts)
 	tracer := cfg.TracerProvider.Tracer(
 		tracerName,
 		// oteltrace.WithInstrumentationVersion(SemVersion()),
 	)
-	if cfg.Propagators == nil {
-		cfg.Propagators = otel.GetTextMapPropagator()
-	}
 
 	return func(c *gin.Context) {
 		c.Set(tracerKey, tracer)
@@ -106,6 +131,42 @@ func GinMiddleware(service string, opts ...TracerOption) gin.HandlerFunc {
 	}
 }
 
+// HttpMiddleware net/http middleware, usage: http.ListenAndServe(addr, otel.HttpMiddleware(service)(mux))
+func HttpMiddleware(service string, opts ...TracerOption) func(http.Handler) http.Handler {
+	cfg := newConfig(opts)
+	tracer := cfg.TracerProvider.Tracer(httpTracerName)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := cfg.Propagators.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			opts := []oteltrace.SpanStartOption{
+				oteltrace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", r)...),
+				oteltrace.WithAttributes(semconv.EndUserAttributesFromHTTPRequest(r)...),
+				oteltrace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(service, "", r)...),
+				oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+			}
+			// the path is unbounded, like /user/1, the route is set by WithHttpSpanName.
+			spanName := "HTTP " + r.Method
+			if cfg.spanNameFunc != nil {
+				spanName = cfg.spanNameFunc(r)
+			}
+			ctx, span := tracer.Start(ctx, spanName, opts...)
+			defer span.End()
+
+			// write trace-id to resp header
+			w.Header().Set(HeaderTraceID, span.SpanContext().TraceID().String())
+
+			rw := httputil.NewResponseWriter(w)
+			next.ServeHTTP(rw.Wrap(), r.WithContext(ctx))
+
+			attrs := semconv.HTTPAttributesFromHTTPStatusCode(rw.Status())
+			spanStatus, spanMessage := semconv.SpanStatusFromHTTPStatusCode(rw.Status())
+			span.SetAttributes(attrs...)
+			span.SetStatus(spanStatus, spanMessage)
+		})
+	}
+}
+
 func HTML(c *gin.Context, code int, name string, obj interface{}) {
 	var tracer oteltrace.Tracer
 	tracerInterface, ok := c.Get(tracerKey)
@@ -0,0 +1,131 @@
+package otel
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Transport http.RoundTripper which create client span for each request.
+type Transport struct {
+	base http.RoundTripper
+	cfg  config
+
+	tracer oteltrace.Tracer
+}
+
+var _ http.RoundTripper = &Transport{}
+
+// NewTransport wrap base RoundTripper, use http.DefaultTransport when base is nil.
+func NewTransport(base http.RoundTripper, opts ...TracerOption) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	cfg := newConfig(opts)
+	return &Transport{
+		base:   base,
+		cfg:    cfg,
+		tracer: cfg.TracerProvider.Tracer(httpTracerName),
+	}
+}
+
+// WrapHttpClient return a copy of client with traced transport, use http.DefaultClient when client is nil.
+func WrapHttpClient(client *http.Client, opts ...TracerOption) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	cli := *client
+	cli.Transport = NewTransport(client.Transport, opts...)
+	return &cli
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	opts := []oteltrace.SpanStartOption{
+		oteltrace.WithAttributes(semconv.HTTPClientAttributesFromHTTPRequest(req)...),
+		oteltrace.WithAttributes(semconv.NetPeerNameKey.String(req.URL.Hostname())),
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	}
+	if port := req.URL.Port(); port != "" {
+		opts = append(opts, oteltrace.WithAttributes(semconv.NetPeerPortKey.Int(cast.ToInt(port))))
+	}
+	ctx, span := t.tracer.Start(req.Context(), fmt.Sprintf("HTTP %s", req.Method), opts...)
+	defer span.End()
+
+	if t.cfg.clientTrace {
+		ctx = httptrace.WithClientTrace(ctx, newClientTrace(span))
+	}
+
+	// RoundTripper should not modify the origin request.
+	req = req.Clone(ctx)
+	t.cfg.Propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, err
+}
+
+// newClientTrace add the connection phase events to span.
+func newClientTrace(span oteltrace.Span) *httptrace.ClientTrace {
+	addEvent := func(event string, err error, attrs ...attribute.KeyValue) {
+		if err != nil {
+			attrs = append(attrs, attribute.String("error", err.Error()))
+		}
+		span.AddEvent(event, oteltrace.WithAttributes(attrs...))
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			addEvent("dns.start", nil, attribute.String("host", info.Host))
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			addrs := make([]string, 0, len(info.Addrs))
+			for _, addr := range info.Addrs {
+				addrs = append(addrs, addr.String())
+			}
+			addEvent("dns.done", info.Err, attribute.String("addrs", strings.Join(addrs, ",")))
+		},
+		ConnectStart: func(network, addr string) {
+			addEvent("connect.start", nil, attribute.String("network", network), attribute.String("addr", addr))
+		},
+		ConnectDone: func(network, addr string, err error) {
+			addEvent("connect.done", err, attribute.String("network", network), attribute.String("addr", addr))
+		},
+		TLSHandshakeStart: func() {
+			addEvent("tls.start", nil)
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			addEvent("tls.done", err, attribute.Bool("resumed", state.DidResume), attribute.String("server_name", state.ServerName))
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			addEvent("got_conn", nil,
+				attribute.Bool("reused", info.Reused),
+				attribute.Bool("was_idle", info.WasIdle),
+				attribute.String("idle_time", info.IdleTime.String()),
+			)
+		},
+		GotFirstResponseByte: func() {
+			addEvent("first_response_byte", nil)
+		},
+	}
+}
@@ -0,0 +1,136 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanEvents returns the event names of span, and the attributes of each event.
+func spanEvents(span tracesdk.ReadOnlySpan) ([]string, map[string]map[string]string) {
+	var events []string
+	attrs := make(map[string]map[string]string)
+	for _, event := range span.Events() {
+		kv := make(map[string]string)
+		for _, attr := range event.Attributes {
+			kv[string(attr.Key)] = attr.Value.Emit()
+		}
+		events = append(events, event.Name)
+		attrs[event.Name] = kv
+	}
+	return events, attrs
+}
+
+func TestTransportClientTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// resolve localhost to trace the dns phase.
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/ping"
+	client := WrapHttpClient(&http.Client{Transport: &http.Transport{}}, WithTracerProvider(provider), WithClientTrace())
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(url)
+		assert.Nil(t, err)
+		resp.Body.Close()
+	}
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	events, attrs := spanEvents(spans[0])
+	assert.Equal(t, []string{"dns.start", "dns.done", "connect.start", "connect.done", "got_conn", "first_response_byte"}, events)
+	assert.Equal(t, "localhost", attrs["dns.start"]["host"])
+	assert.Contains(t, attrs["dns.done"]["addrs"], "127.0.0.1")
+	assert.Equal(t, "tcp", attrs["connect.start"]["network"])
+	assert.Equal(t, server.Listener.Addr().String(), attrs["connect.done"]["addr"])
+	assert.Empty(t, attrs["connect.done"]["error"])
+	assert.Equal(t, "false", attrs["got_conn"]["reused"])
+
+	// the idle connection is reused without dns and connect.
+	events, attrs = spanEvents(spans[1])
+	assert.Equal(t, []string{"got_conn", "first_response_byte"}, events)
+	assert.Equal(t, "true", attrs["got_conn"]["reused"])
+	assert.Equal(t, "true", attrs["got_conn"]["was_idle"])
+}
+
+func TestTransportClientTraceError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := WrapHttpClient(&http.Client{Transport: &http.Transport{}}, WithTracerProvider(provider), WithClientTrace())
+	_, err := client.Get(server.URL + "/ping")
+	assert.NotNil(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+	// the error of RecordError is the last event.
+	events, attrs := spanEvents(spans[0])
+	assert.Equal(t, []string{"connect.start", "connect.done", "exception"}, events)
+	assert.Contains(t, attrs["connect.done"]["error"], "connection refused")
+}
+
+func TestHttpMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	middleware := HttpMiddleware("api", WithTracerProvider(provider), WithPropagators(propagation.TraceContext{}))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "client")
+	parent.End()
+	req := httptest.NewRequest(http.MethodGet, "/user/1", nil)
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp := httptest.NewRecorder()
+	middleware(handler).ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// the path isn't the span name, it's unbounded.
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	span := spans[1]
+	assert.Equal(t, "HTTP GET", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Contains(t, span.Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusNotFound))
+	assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.True(t, span.Parent().IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID().String(), resp.Header().Get(HeaderTraceID))
+}
+
+func TestHttpMiddlewareSpanName(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	middleware := HttpMiddleware("api", WithTracerProvider(provider), WithHttpSpanName(func(r *http.Request) string {
+		return r.Method + " /user/:id"
+	}))
+	middleware(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/user/1", nil))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "POST /user/:id", spans[0].Name())
+	assert.False(t, spans[0].Parent().IsValid())
+	assert.Contains(t, spans[0].Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusOK))
+}
@@ -0,0 +1,101 @@
+// Package kafkatrace propagate the span context by the headers of kafka message, support sarama and kafka-go,
+// the span context is injected by w3c trace context and x-trace-id of jaeger format.
+package kafkatrace
+
+import (
+	"context"
+
+	tracer "github.com/rfyiamcool/go-tracer/otel"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/rfyiamcool/go-tracer/otel/kafkatrace"
+
+type config struct {
+	tracer      trace.Tracer
+	propagators propagation.TextMapPropagator
+}
+
+// Option specifies kafka instrumentation configuration options.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithTracerProvider default: the global tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.tracer = provider.Tracer(tracerName)
+	})
+}
+
+// WithPropagators default: GetCarrierPropagator of go-tracer/otel, resolved at the time of injecting and extracting.
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.propagators = propagators
+	})
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		tracer: otel.GetTracerProvider().Tracer(tracerName),
+	}
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+	return cfg
+}
+
+func (cfg *config) propagator() propagation.TextMapPropagator {
+	if cfg.propagators != nil {
+		return cfg.propagators
+	}
+	return tracer.GetCarrierPropagator()
+}
+
+func (cfg *config) inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	cfg.propagator().Inject(ctx, carrier)
+}
+
+func (cfg *config) extract(carrier propagation.TextMapCarrier) (trace.SpanContext, bool) {
+	sc := trace.SpanContextFromContext(cfg.propagator().Extract(context.Background(), carrier))
+	return sc, sc.IsValid()
+}
+
+type consumerMessage struct {
+	topic     string
+	partition int
+	offset    int64
+	group     string
+}
+
+// startConsumerSpan start span which links to the producer, and is child of the span in context.
+func (cfg *config) startConsumerSpan(ctx context.Context, carrier propagation.TextMapCarrier, msg consumerMessage) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("kafka"),
+			semconv.MessagingDestinationKindTopic,
+			semconv.MessagingDestinationKey.String(msg.topic),
+			semconv.MessagingOperationProcess,
+			semconv.MessagingKafkaPartitionKey.Int(msg.partition),
+			semconv.MessagingMessageIDKey.Int64(msg.offset),
+		),
+	}
+	if msg.group != "" {
+		opts = append(opts, trace.WithAttributes(semconv.MessagingKafkaConsumerGroupKey.String(msg.group)))
+	}
+	if producer, ok := cfg.extract(carrier); ok {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: producer}))
+	}
+
+	return cfg.tracer.Start(ctx, msg.topic+" process", opts...)
+}
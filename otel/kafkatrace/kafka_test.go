@@ -0,0 +1,108 @@
+package kafkatrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/rfyiamcool/go-tracer/internal/otelutil"
+	tracer "github.com/rfyiamcool/go-tracer/otel"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+func newTestProvider() (*tracesdk.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder)), recorder
+}
+
+func TestSarama(t *testing.T) {
+	provider, recorder := newTestProvider()
+	ctx, producerSpan := provider.Tracer("test").Start(context.Background(), "produce")
+	producerSpan.End()
+
+	var headers []*sarama.RecordHeader
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		for i := range msg.Headers {
+			headers = append(headers, &msg.Headers[i])
+		}
+		return nil
+	})
+
+	msg := &sarama.ProducerMessage{Topic: "orders", Value: sarama.StringEncoder("value")}
+	InjectSarama(ctx, msg)
+	_, _, err := producer.SendMessage(msg)
+	assert.Nil(t, err)
+	assert.Nil(t, producer.Close())
+
+	carrier := saramaConsumerCarrier(headers)
+	assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`, carrier.Get("traceparent"))
+	assert.Equal(t, producerSpan.SpanContext().TraceID().String()+":"+producerSpan.SpanContext().SpanID().String()+":0:01", carrier.Get("x-trace-id"))
+
+	_, span := StartSaramaConsumerSpan(context.Background(), "group", &sarama.ConsumerMessage{
+		Topic: "orders", Partition: 1, Offset: 10, Headers: headers,
+	}, WithTracerProvider(provider))
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	consumer := spans[1]
+	assert.Equal(t, "orders process", consumer.Name())
+	assert.False(t, consumer.Parent().IsValid())
+	assert.Len(t, consumer.Links(), 1)
+	assert.Equal(t, producerSpan.SpanContext().SpanID(), consumer.Links()[0].SpanContext.SpanID())
+	assert.Contains(t, consumer.Attributes(), semconv.MessagingKafkaPartitionKey.Int(1))
+	assert.Contains(t, consumer.Attributes(), semconv.MessagingKafkaConsumerGroupKey.String("group"))
+}
+
+func TestKafkaGo(t *testing.T) {
+	provider, recorder := newTestProvider()
+	_, producerSpan := provider.Tracer("test").Start(context.Background(), "produce")
+	producerSpan.End()
+
+	// only x-trace-id, like the message produced by opentracing jaeger.
+	sc := producerSpan.SpanContext()
+	received := kafka.Message{
+		Topic:   "orders",
+		Headers: []kafka.Header{{Key: "x-trace-id", Value: []byte(sc.TraceID().String()[16:] + ":" + sc.SpanID().String() + ":0:1")}},
+	}
+
+	parentCtx, parent := provider.Tracer("test").Start(context.Background(), "poll")
+	_, span := StartKafkaConsumerSpan(parentCtx, "", &received, WithTracerProvider(provider))
+	span.End()
+	parent.End()
+
+	consumer := recorder.Ended()[1]
+	assert.Equal(t, parent.SpanContext().SpanID(), consumer.Parent().SpanID())
+	assert.Len(t, consumer.Links(), 1)
+	assert.Equal(t, sc.SpanID(), consumer.Links()[0].SpanContext.SpanID())
+	assert.True(t, consumer.Links()[0].SpanContext.IsSampled())
+}
+
+func TestPropagator(t *testing.T) {
+	provider, _ := newTestProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "produce")
+	span.End()
+
+	// the default follows the propagator set after init, with x-trace-id.
+	defer tracer.SetPropagator(tracer.GetPropagator())
+	tracer.SetPropagator(propagation.TraceContext{})
+
+	msg := kafka.Message{Topic: "orders"}
+	InjectKafka(ctx, &msg)
+	assert.Len(t, msg.Headers, 2)
+	assert.Equal(t, "traceparent", msg.Headers[0].Key)
+	assert.Equal(t, "x-trace-id", msg.Headers[1].Key)
+
+	msg = kafka.Message{Topic: "orders"}
+	InjectKafka(ctx, &msg, WithPropagators(otelutil.XTraceIDPropagator{}))
+	assert.Len(t, msg.Headers, 1)
+	assert.Equal(t, "x-trace-id", msg.Headers[0].Key)
+}
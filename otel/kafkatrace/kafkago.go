@@ -0,0 +1,57 @@
+package kafkatrace
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InjectKafka inject the span context of ctx into the headers of kafka-go message before writing.
+//
+//	msg := kafka.Message{Value: value}
+//	kafkatrace.InjectKafka(ctx, &msg)
+//	writer.WriteMessages(ctx, msg)
+func InjectKafka(ctx context.Context, msg *kafka.Message, opts ...Option) {
+	newConfig(opts).inject(ctx, (*kafkaCarrier)(msg))
+}
+
+// StartKafkaConsumerSpan start consumer span which links to the producer span of kafka-go message,
+// the span has topic, partition, offset and consumer group attributes.
+func StartKafkaConsumerSpan(ctx context.Context, group string, msg *kafka.Message, opts ...Option) (context.Context, trace.Span) {
+	return newConfig(opts).startConsumerSpan(ctx, (*kafkaCarrier)(msg), consumerMessage{
+		topic:     msg.Topic,
+		partition: msg.Partition,
+		offset:    msg.Offset,
+		group:     group,
+	})
+}
+
+type kafkaCarrier kafka.Message
+
+func (c *kafkaCarrier) Get(key string) string {
+	for _, header := range c.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c *kafkaCarrier) Set(key, val string) {
+	for i, header := range c.Headers {
+		if header.Key == key {
+			c.Headers[i].Value = []byte(val)
+			return
+		}
+	}
+	c.Headers = append(c.Headers, kafka.Header{Key: key, Value: []byte(val)})
+}
+
+func (c *kafkaCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Headers))
+	for _, header := range c.Headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
@@ -0,0 +1,80 @@
+package kafkatrace
+
+import (
+	"context"
+
+	"github.com/Shopify/sarama"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InjectSarama inject the span context of ctx into the headers of message before sending.
+//
+//	msg := &sarama.ProducerMessage{Topic: topic, Value: sarama.StringEncoder(value)}
+//	kafkatrace.InjectSarama(ctx, msg)
+//	producer.SendMessage(msg)
+func InjectSarama(ctx context.Context, msg *sarama.ProducerMessage, opts ...Option) {
+	newConfig(opts).inject(ctx, (*saramaProducerCarrier)(msg))
+}
+
+// StartSaramaConsumerSpan start consumer span which links to the producer span of message,
+// the span has topic, partition, offset and consumer group attributes.
+func StartSaramaConsumerSpan(ctx context.Context, group string, msg *sarama.ConsumerMessage, opts ...Option) (context.Context, trace.Span) {
+	return newConfig(opts).startConsumerSpan(ctx, saramaConsumerCarrier(msg.Headers), consumerMessage{
+		topic:     msg.Topic,
+		partition: int(msg.Partition),
+		offset:    msg.Offset,
+		group:     group,
+	})
+}
+
+type saramaProducerCarrier sarama.ProducerMessage
+
+func (c *saramaProducerCarrier) Get(key string) string {
+	for _, header := range c.Headers {
+		if string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c *saramaProducerCarrier) Set(key, val string) {
+	for i, header := range c.Headers {
+		if string(header.Key) == key {
+			c.Headers[i].Value = []byte(val)
+			return
+		}
+	}
+	c.Headers = append(c.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(val)})
+}
+
+func (c *saramaProducerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Headers))
+	for _, header := range c.Headers {
+		keys = append(keys, string(header.Key))
+	}
+	return keys
+}
+
+type saramaConsumerCarrier []*sarama.RecordHeader
+
+func (c saramaConsumerCarrier) Get(key string) string {
+	for _, header := range c {
+		if header != nil && string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c saramaConsumerCarrier) Set(key, val string) {}
+
+func (c saramaConsumerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for _, header := range c {
+		if header != nil {
+			keys = append(keys, string(header.Key))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,75 @@
+// Package logrustrace correlate the logrus logs with the opentelemetry spans, add trace_id and
+// span_id of the entry context, and mirror the logs to the span events optionally.
+package logrustrace
+
+import (
+	"github.com/rfyiamcool/go-tracer/internal/logutil"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Hook add trace_id and span_id to the entry which has context.
+type Hook struct {
+	spanEvent bool
+	spanLevel logrus.Level
+}
+
+var _ logrus.Hook = &Hook{}
+
+type Option func(*Hook)
+
+// WithSpanEvent mirror the entries at level or above to the span events of context, like logrus.WarnLevel.
+func WithSpanEvent(level logrus.Level) Option {
+	return func(hook *Hook) {
+		hook.spanEvent = true
+		hook.spanLevel = level
+	}
+}
+
+// NewHook creates the hook, the context of entry is set by WithContext.
+//
+//	logrus.AddHook(logrustrace.NewHook(logrustrace.WithSpanEvent(logrus.WarnLevel)))
+//	logrus.WithContext(ctx).WithField("id", id).Info("get user")
+func NewHook(opts ...Option) *Hook {
+	hook := &Hook{}
+	for _, opt := range opts {
+		opt(hook)
+	}
+	return hook
+}
+
+// Levels implements logrus.Hook.
+func (hook *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (hook *Hook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	span := trace.SpanFromContext(entry.Context)
+	if sc := span.SpanContext(); sc.IsValid() {
+		entry.Data[logutil.TraceIDKey] = sc.TraceID().String()
+		entry.Data[logutil.SpanIDKey] = sc.SpanID().String()
+	}
+
+	// the lower level is more severe in logrus.
+	if !span.IsRecording() || !hook.spanEvent || entry.Level > hook.spanLevel {
+		return nil
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String(logutil.LevelKey, entry.Level.String()),
+		attribute.String(logutil.MessageKey, entry.Message),
+	}
+	for _, k := range logutil.SortedKeys(entry.Data) {
+		if k == logutil.TraceIDKey || k == logutil.SpanIDKey {
+			continue
+		}
+		attrs = append(attrs, logutil.Attribute(k, entry.Data[k]))
+	}
+	span.AddEvent(logutil.EventLog, trace.WithAttributes(attrs...))
+	return nil
+}
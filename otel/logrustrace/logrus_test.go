@@ -0,0 +1,50 @@
+package logrustrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestHook(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "get-user")
+	sc := span.SpanContext()
+
+	logger, hook := test.NewNullLogger()
+	logger.AddHook(NewHook(WithSpanEvent(logrus.WarnLevel)))
+
+	logger.WithContext(ctx).WithField("id", 1).Info("get user")
+	logger.WithContext(ctx).WithField("cost", 300).Warn("slow query")
+	logger.WithContext(context.Background()).Warn("no span")
+	logger.Error("no context")
+	span.End()
+
+	entries := hook.AllEntries()
+	assert.Len(t, entries, 4)
+	for _, entry := range entries[:2] {
+		assert.Equal(t, sc.TraceID().String(), entry.Data["trace_id"])
+		assert.Equal(t, sc.SpanID().String(), entry.Data["span_id"])
+	}
+	assert.Equal(t, 1, entries[0].Data["id"])
+	assert.Empty(t, entries[2].Data)
+	assert.Empty(t, entries[3].Data)
+
+	// only the warn log with span is mirrored.
+	events := recorder.Ended()[0].Events()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "log", events[0].Name)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("log.severity", "warning"),
+		attribute.String("log.message", "slow query"),
+		attribute.Int("cost", 300),
+	}, events[0].Attributes)
+}
@@ -0,0 +1,120 @@
+// Package mongotrace trace the commands of mongo-driver by opentelemetry.
+package mongotrace
+
+import (
+	"context"
+
+	"github.com/rfyiamcool/go-tracer/internal/mongoutil"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/rfyiamcool/go-tracer/otel/mongotrace"
+
+type monitor struct {
+	tracer trace.Tracer
+
+	statement    bool
+	skipCommands map[string]bool
+	spans        mongoutil.SpanMap
+}
+
+type Option func(*monitor)
+
+// WithTracerProvider default: the global tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(m *monitor) {
+		m.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// WithoutStatement don't add db.statement attribute, the values of statement are always sanitized.
+func WithoutStatement() Option {
+	return func(m *monitor) {
+		m.statement = false
+	}
+}
+
+// WithSkipCommands don't trace the noisy commands, like ping, hello.
+func WithSkipCommands(names ...string) Option {
+	return func(m *monitor) {
+		for _, name := range names {
+			m.skipCommands[name] = true
+		}
+	}
+}
+
+// NewMonitor creates a new command monitor which collect spans,
+//
+//	opts := options.Client().ApplyURI(uri).SetMonitor(mongotrace.NewMonitor())
+//	client, err := mongo.Connect(ctx, opts)
+func NewMonitor(opts ...Option) *event.CommandMonitor {
+	m := &monitor{
+		tracer:       otel.GetTracerProvider().Tracer(tracerName),
+		statement:    true,
+		skipCommands: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+func (m *monitor) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	if m.skipCommands[evt.CommandName] {
+		return
+	}
+
+	collection := mongoutil.Collection(evt.Command)
+	name := evt.CommandName
+	if collection != "" {
+		name = collection + "." + evt.CommandName
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemMongoDB,
+		semconv.DBNameKey.String(evt.DatabaseName),
+		semconv.DBOperationKey.String(evt.CommandName),
+		semconv.DBMongoDBCollectionKey.String(collection),
+	}
+	host, port := mongoutil.PeerHostPort(evt.ConnectionID)
+	attrs = append(attrs, semconv.NetPeerNameKey.String(host))
+	if port > 0 {
+		attrs = append(attrs, semconv.NetPeerPortKey.Int(port))
+	}
+	if m.statement {
+		attrs = append(attrs, semconv.DBStatementKey.String(mongoutil.Statement(evt.Command)))
+	}
+
+	_, span := m.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	m.spans.Store(evt.ConnectionID, evt.RequestID, span)
+}
+
+func (m *monitor) succeeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	if span, ok := m.spans.LoadAndDelete(evt.ConnectionID, evt.RequestID); ok {
+		span.(trace.Span).End()
+	}
+}
+
+func (m *monitor) failed(ctx context.Context, evt *event.CommandFailedEvent) {
+	val, ok := m.spans.LoadAndDelete(evt.ConnectionID, evt.RequestID)
+	if !ok {
+		return
+	}
+
+	span := val.(trace.Span)
+	span.SetStatus(codes.Error, evt.Failure)
+	span.End()
+}
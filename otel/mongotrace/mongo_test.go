@@ -0,0 +1,49 @@
+package mongotrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+func TestMonitor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+	monitor := NewMonitor(WithTracerProvider(provider))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+
+	command, _ := bson.Marshal(bson.D{{Key: "insert", Value: "users"}, {Key: "documents", Value: bson.A{bson.D{{Key: "name", Value: "bob"}}}}})
+	for _, requestID := range []int64{1, 2} {
+		monitor.Started(ctx, &event.CommandStartedEvent{
+			Command:      command,
+			DatabaseName: "test",
+			CommandName:  "insert",
+			RequestID:    requestID,
+			ConnectionID: "localhost:27017[-1]",
+		})
+	}
+	finished := func(requestID int64) event.CommandFinishedEvent {
+		return event.CommandFinishedEvent{CommandName: "insert", RequestID: requestID, ConnectionID: "localhost:27017[-1]"}
+	}
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished(2)})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished(1), Failure: "duplicate key"})
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	succeeded, failed := spans[0], spans[1]
+	assert.Equal(t, "users.insert", succeeded.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), succeeded.Parent().SpanID())
+	assert.Contains(t, succeeded.Attributes(), semconv.DBMongoDBCollectionKey.String("users"))
+	assert.Contains(t, succeeded.Attributes(), semconv.DBStatementKey.String(`{"insert":"users","documents":[{"name":"?"}]}`))
+	assert.Equal(t, codes.Unset, succeeded.Status().Code)
+	assert.Equal(t, codes.Error, failed.Status().Code)
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rfyiamcool/go-tracer/internal/otelutil"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/jaeger"
@@ -22,7 +23,7 @@ import (
 var (
 	hostname, _       = os.Hostname()
 	tracerProvider    *tracesdk.TracerProvider
-	defaultPropagator propagation.TextMapPropagator
+	defaultPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
 
 	maxQueueSize = 5000
 )
@@ -180,11 +181,6 @@ func New(serviceName string, fns ...optionFunc) (*tracesdk.TracerProvider, error
 		)),
 	)
 
-	defaultPropagator = propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	)
-
 	// set global
 	otel.SetTracerProvider(tracerProvider)
 	otel.SetTextMapPropagator(defaultPropagator)
@@ -211,11 +207,16 @@ func GetTracerProvider() *tracesdk.TracerProvider {
 	return tracerProvider
 }
 
-// GetPropagator
+// GetPropagator default: w3c trace context and baggage.
 func GetPropagator() propagation.TextMapPropagator {
 	return defaultPropagator
 }
 
+// GetCarrierPropagator return GetPropagator with x-trace-id, used by the message carriers only.
+func GetCarrierPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(defaultPropagator, otelutil.XTraceIDPropagator{})
+}
+
 // SetTracerProvider
 func SetTracerProvider(provider *tracesdk.TracerProvider) {
 	tracerProvider = provider
@@ -0,0 +1,127 @@
+package otel
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rfyiamcool/go-tracer/internal/redisutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const redisTracerName = "github.com/rfyiamcool/go-tracer/otel/redis"
+
+type RedisTracingHook struct {
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+}
+
+var _ redis.Hook = &RedisTracingHook{}
+
+type RedisOption func(*RedisTracingHook)
+
+// WithRedisAddr add net.peer.name and net.peer.port attributes by the redis address, like 127.0.0.1:6379
+func WithRedisAddr(addr string) RedisOption {
+	return func(hook *RedisTracingHook) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			hook.attrs = append(hook.attrs, semconv.NetPeerNameKey.String(addr))
+			return
+		}
+
+		hook.attrs = append(hook.attrs, semconv.NetPeerNameKey.String(host))
+		if n, err := strconv.Atoi(port); err == nil {
+			hook.attrs = append(hook.attrs, semconv.NetPeerPortKey.Int(n))
+		}
+	}
+}
+
+// WithRedisTracerProvider default: the global tracer provider.
+func WithRedisTracerProvider(provider trace.TracerProvider) RedisOption {
+	return func(hook *RedisTracingHook) {
+		hook.tracer = provider.Tracer(redisTracerName)
+	}
+}
+
+// NewRedisHook creates a new go-redis hook instance and that will collect spans,
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: addr})
+//	rdb.AddHook(otel.NewRedisHook(otel.WithRedisAddr(addr)))
+func NewRedisHook(opts ...RedisOption) redis.Hook {
+	hook := &RedisTracingHook{
+		tracer: otel.GetTracerProvider().Tracer(redisTracerName),
+		attrs:  []attribute.KeyValue{semconv.DBSystemRedis},
+	}
+	for _, opt := range opts {
+		opt(hook)
+	}
+	return hook
+}
+
+func (hook *RedisTracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, _ = hook.tracer.Start(ctx, cmd.FullName(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(hook.attrs...),
+		trace.WithAttributes(
+			semconv.DBOperationKey.String(cmd.Name()),
+			semconv.DBStatementKey.String(redisutil.Statement(cmd.Args())),
+		),
+	)
+	return ctx, nil
+}
+
+func (hook *RedisTracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if err := cmd.Err(); err != nil {
+		recordRedisError(span, err)
+	}
+	return nil
+}
+
+func (hook *RedisTracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	ctx, _ = hook.tracer.Start(ctx, "pipeline",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(hook.attrs...),
+		trace.WithAttributes(attribute.Int("db.redis.num_cmd", len(cmds))),
+	)
+	return ctx, nil
+}
+
+func (hook *RedisTracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	var firstErr error
+	for _, cmd := range cmds {
+		attrs := []attribute.KeyValue{
+			semconv.DBStatementKey.String(redisutil.Statement(cmd.Args())),
+		}
+		if err := cmd.Err(); err != nil && err != redis.Nil {
+			attrs = append(attrs, attribute.String("db.error", err.Error()))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		span.AddEvent(cmd.FullName(), trace.WithAttributes(attrs...))
+	}
+
+	if firstErr != nil {
+		recordRedisError(span, firstErr)
+	}
+	return nil
+}
+
+func recordRedisError(span trace.Span, err error) {
+	if err == redis.Nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
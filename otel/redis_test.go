@@ -0,0 +1,38 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestRedisHook(t *testing.T) {
+	recorder := newTestRecorder()
+	hook := NewRedisHook(WithRedisAddr("127.0.0.1:6379"))
+	ctx := context.Background()
+
+	cmd := redis.NewStringCmd(ctx, "get", "key")
+	cctx, _ := hook.BeforeProcess(ctx, cmd)
+	cmd.SetErr(redis.Nil)
+	hook.AfterProcess(cctx, cmd)
+
+	cmds := []redis.Cmder{redis.NewStatusCmd(ctx, "set", "key", "val"), redis.NewStringCmd(ctx, "get", "key")}
+	cmds[1].SetErr(errors.New("timeout"))
+	cctx, _ = hook.BeforeProcessPipeline(ctx, cmds)
+	hook.AfterProcessPipeline(cctx, cmds)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	assert.Equal(t, "get", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "pipeline", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "set", spans[1].Events()[0].Name)
+	assert.Equal(t, "get", spans[1].Events()[1].Name)
+}
@@ -0,0 +1,180 @@
+// Package redisv9 trace go-redis v9 by opentelemetry, the hook of go-redis v8 is otel.NewRedisHook.
+package redisv9
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rfyiamcool/go-tracer/internal/redisutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/rfyiamcool/go-tracer/otel/redisv9"
+
+type TracingHook struct {
+	tracer trace.Tracer
+
+	formatter    redisutil.Formatter
+	skipCommands map[string]bool
+	attrs        []attribute.KeyValue
+}
+
+var _ redis.Hook = &TracingHook{}
+
+type Option func(*TracingHook)
+
+// WithTracerProvider default: the global tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(hook *TracingHook) {
+		hook.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// WithSanitize only keep the command name and key in db.statement, drop the values.
+func WithSanitize() Option {
+	return func(hook *TracingHook) {
+		hook.formatter.Sanitize = true
+	}
+}
+
+// WithMaxArgLength truncate the arg longer than size in db.statement.
+func WithMaxArgLength(size int) Option {
+	return func(hook *TracingHook) {
+		hook.formatter.MaxArgLength = size
+	}
+}
+
+// WithSkipCommands don't trace the noisy commands, like ping.
+func WithSkipCommands(names ...string) Option {
+	return func(hook *TracingHook) {
+		for _, name := range names {
+			hook.skipCommands[strings.ToLower(name)] = true
+		}
+	}
+}
+
+// WithOptions add net.peer.name, net.peer.port and db.redis.database_index attributes by the options of redis client.
+func WithOptions(opt *redis.Options) Option {
+	return func(hook *TracingHook) {
+		hook.attrs = append(hook.attrs, peerAttrs(opt.Addr)...)
+		hook.attrs = append(hook.attrs, semconv.DBRedisDBIndexKey.Int(opt.DB))
+	}
+}
+
+// WithClusterOptions add the node addresses attribute by the options of redis cluster client.
+func WithClusterOptions(opt *redis.ClusterOptions) Option {
+	return func(hook *TracingHook) {
+		hook.attrs = append(hook.attrs, attribute.StringSlice("db.redis.cluster_nodes", opt.Addrs))
+	}
+}
+
+// NewHook creates a new go-redis v9 hook which collect spans,
+//
+//	rdb := redis.NewClient(opt)
+//	rdb.AddHook(redisv9.NewHook(redisv9.WithOptions(opt)))
+func NewHook(opts ...Option) *TracingHook {
+	hook := &TracingHook{
+		tracer:       otel.GetTracerProvider().Tracer(tracerName),
+		skipCommands: make(map[string]bool),
+		attrs:        []attribute.KeyValue{semconv.DBSystemRedis},
+	}
+	for _, opt := range opts {
+		opt(hook)
+	}
+	return hook
+}
+
+func (hook *TracingHook) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return hook.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(hook.attrs...),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// DialHook trace the connection establishment.
+func (hook *TracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := hook.start(ctx, "redis.dial", peerAttrs(addr)...)
+		defer span.End()
+
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			recordError(span, err)
+		}
+		return conn, err
+	}
+}
+
+// ProcessHook trace the command.
+func (hook *TracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if hook.skipCommands[cmd.Name()] {
+			return next(ctx, cmd)
+		}
+
+		ctx, span := hook.start(ctx, cmd.FullName(),
+			semconv.DBOperationKey.String(cmd.Name()),
+			semconv.DBStatementKey.String(hook.formatter.Statement(cmd.Args())),
+		)
+		defer span.End()
+
+		err := next(ctx, cmd)
+		if err != nil {
+			recordError(span, err)
+		}
+		return err
+	}
+}
+
+// ProcessPipelineHook trace the pipeline, each command is added as event.
+func (hook *TracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := hook.start(ctx, "pipeline", attribute.Int("db.redis.num_cmd", len(cmds)))
+		defer span.End()
+
+		err := next(ctx, cmds)
+		for _, cmd := range cmds {
+			attrs := []attribute.KeyValue{
+				semconv.DBStatementKey.String(hook.formatter.Statement(cmd.Args())),
+			}
+			if cerr := cmd.Err(); cerr != nil && cerr != redis.Nil {
+				attrs = append(attrs, attribute.String("db.error", cerr.Error()))
+			}
+			span.AddEvent(cmd.FullName(), trace.WithAttributes(attrs...))
+		}
+
+		if err != nil {
+			recordError(span, err)
+		}
+		return err
+	}
+}
+
+func peerAttrs(addr string) []attribute.KeyValue {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return []attribute.KeyValue{semconv.NetPeerNameKey.String(addr)}
+	}
+
+	attrs := []attribute.KeyValue{semconv.NetPeerNameKey.String(host)}
+	if n, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.NetPeerPortKey.Int(n))
+	}
+	return attrs
+}
+
+func recordError(span trace.Span, err error) {
+	if err == redis.Nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,119 @@
+package redisv9
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+func newTestProvider() (*tracesdk.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder)), recorder
+}
+
+func TestHook(t *testing.T) {
+	provider, recorder := newTestProvider()
+
+	opt := &redis.Options{
+		Addr:       "127.0.0.1:6379",
+		DB:         1,
+		MaxRetries: -1,
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	rdb := redis.NewClient(opt)
+	defer rdb.Close()
+	rdb.AddHook(NewHook(WithTracerProvider(provider), WithOptions(opt), WithSanitize(), WithSkipCommands("PING")))
+
+	ctx := context.Background()
+	assert.NotNil(t, rdb.Ping(ctx).Err())
+	assert.NotNil(t, rdb.Set(ctx, "key", "secret", 0).Err())
+
+	var dials, sets int
+	for _, span := range recorder.Ended() {
+		assert.Contains(t, span.Attributes(), semconv.DBSystemRedis)
+		assert.Contains(t, span.Attributes(), semconv.NetPeerNameKey.String("127.0.0.1"))
+		assert.Contains(t, span.Attributes(), semconv.NetPeerPortKey.Int(6379))
+		assert.Equal(t, codes.Error, span.Status().Code)
+
+		switch span.Name() {
+		case "redis.dial":
+			dials++
+		case "set":
+			sets++
+			assert.Contains(t, span.Attributes(), semconv.DBRedisDBIndexKey.Int(1))
+			assert.Contains(t, span.Attributes(), semconv.DBOperationKey.String("set"))
+			assert.Contains(t, span.Attributes(), semconv.DBStatementKey.String("set key"))
+		default:
+			t.Errorf("unexpected span %s", span.Name())
+		}
+	}
+	assert.True(t, dials > 0)
+	assert.Equal(t, 1, sets)
+}
+
+func TestProcessHookNil(t *testing.T) {
+	provider, recorder := newTestProvider()
+	hook := NewHook(WithTracerProvider(provider))
+
+	ctx := context.Background()
+	process := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		cmd.SetErr(redis.Nil)
+		return redis.Nil
+	})
+	assert.Equal(t, redis.Nil, process(ctx, redis.NewStringCmd(ctx, "get", "key")))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "get", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Empty(t, spans[0].Events())
+}
+
+func TestProcessPipelineHook(t *testing.T) {
+	provider, recorder := newTestProvider()
+	hook := NewHook(WithTracerProvider(provider), WithSkipCommands("ping"))
+
+	ctx := context.Background()
+	timeout := errors.New("timeout")
+	pipeline := hook.ProcessPipelineHook(func(ctx context.Context, cmds []redis.Cmder) error {
+		cmds[1].SetErr(redis.Nil)
+		cmds[2].SetErr(timeout)
+		return timeout
+	})
+	cmds := []redis.Cmder{
+		redis.NewStatusCmd(ctx, "set", "key", "val"),
+		redis.NewStringCmd(ctx, "get", "key"),
+		redis.NewStatusCmd(ctx, "ping"),
+	}
+	assert.Equal(t, timeout, pipeline(ctx, cmds))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "pipeline", span.Name())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), attribute.Int("db.redis.num_cmd", 3))
+
+	// the skip commands are kept in the pipeline, redis.Nil isn't an error.
+	events := span.Events()
+	assert.Len(t, events, 4)
+	assert.Equal(t, "set", events[0].Name)
+	assert.Equal(t, []attribute.KeyValue{semconv.DBStatementKey.String("set key val")}, events[0].Attributes)
+	assert.Equal(t, "get", events[1].Name)
+	assert.Equal(t, []attribute.KeyValue{semconv.DBStatementKey.String("get key")}, events[1].Attributes)
+	assert.Equal(t, "ping", events[2].Name)
+	assert.Contains(t, events[2].Attributes, attribute.String("db.error", "timeout"))
+	assert.Equal(t, "exception", events[3].Name)
+}
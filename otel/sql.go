@@ -0,0 +1,152 @@
+package otel
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/rfyiamcool/go-tracer/internal/sqlhook"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const sqlTracerName = "github.com/rfyiamcool/go-tracer/otel/sql"
+
+type sqlTracer struct {
+	tracer    trace.Tracer
+	formatter sqlhook.Formatter
+	attrs     []attribute.KeyValue
+
+	comment     bool
+	application string
+}
+
+// SQLOption database/sql driver wrapper option
+type SQLOption func(*sqlTracer)
+
+// WithSQLArgs add the args of query to db.args attribute, the args are not recorded by default.
+func WithSQLArgs() SQLOption {
+	return func(t *sqlTracer) {
+		t.formatter.Args = true
+	}
+}
+
+// WithSQLRedact replace the literals of db.statement and the values of db.args with `?`.
+func WithSQLRedact() SQLOption {
+	return func(t *sqlTracer) {
+		t.formatter.Redact = true
+	}
+}
+
+// WithSQLSystem set db.system attribute, like semconv.DBSystemMySQL. default: other_sql
+func WithSQLSystem(system attribute.KeyValue) SQLOption {
+	return func(t *sqlTracer) {
+		t.attrs[0] = system
+	}
+}
+
+// WithSQLName add db.name attribute by the database name.
+func WithSQLName(name string) SQLOption {
+	return func(t *sqlTracer) {
+		t.attrs = append(t.attrs, semconv.DBNameKey.String(name))
+	}
+}
+
+// WithSQLTracerProvider default: the global tracer provider.
+func WithSQLTracerProvider(provider trace.TracerProvider) SQLOption {
+	return func(t *sqlTracer) {
+		t.tracer = provider.Tracer(sqlTracerName)
+	}
+}
+
+// WithSQLComment append sqlcommenter comment with traceparent, tracestate, route and application to the statement,
+// like `select 1 /*application='api',traceparent='00-...-01'*/`. the route is set by ContextWithSQLRoute.
+func WithSQLComment(application string) SQLOption {
+	return func(t *sqlTracer) {
+		t.comment = true
+		t.application = application
+	}
+}
+
+// ContextWithSQLRoute set the route of sql comment, like `/api/user/:id`.
+func ContextWithSQLRoute(ctx context.Context, route string) context.Context {
+	return sqlhook.ContextWithRoute(ctx, route)
+}
+
+// ContextWithoutSQLComment don't comment the statements with the context, the comment makes
+// the server-side cache of prepared statements useless. the prepared statements are commented
+// with the application only, the traceparent and route of the preparing caller would be carried
+// by all the later calls of the statement.
+func ContextWithoutSQLComment(ctx context.Context) context.Context {
+	return sqlhook.ContextWithoutComment(ctx)
+}
+
+func newSQLTracer(opts []SQLOption) *sqlTracer {
+	t := &sqlTracer{
+		tracer: otel.GetTracerProvider().Tracer(sqlTracerName),
+		attrs:  []attribute.KeyValue{semconv.DBSystemOtherSQL},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WrapDriver return a driver which creates spans for the query, exec, prepare, transaction and rows,
+// the spans are children of the span in the context.
+//
+//	sql.Register("mysql-trace", otel.WrapDriver(&mysql.MySQLDriver{}, otel.WithSQLSystem(semconv.DBSystemMySQL)))
+//	db, err := sql.Open("mysql-trace", dsn)
+func WrapDriver(d driver.Driver, opts ...SQLOption) driver.Driver {
+	return sqlhook.WrapDriver(d, newSQLTracer(opts))
+}
+
+// WrapConnector return a connector for sql.OpenDB, like WrapDriver.
+func WrapConnector(c driver.Connector, opts ...SQLOption) driver.Connector {
+	return sqlhook.WrapConnector(c, newSQLTracer(opts))
+}
+
+func (t *sqlTracer) Start(ctx context.Context, op string, query string, args []driver.NamedValue) (context.Context, sqlhook.Span) {
+	attrs := t.attrs
+	if query != "" {
+		attrs = append(attrs[:len(attrs):len(attrs)], semconv.DBStatementKey.String(t.formatter.Statement(query)))
+	}
+	if s := t.formatter.FormatArgs(args); s != "" {
+		attrs = append(attrs[:len(attrs):len(attrs)], attribute.String("db.args", s))
+	}
+
+	ctx, span := t.tracer.Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx, &sqlSpan{span: span}
+}
+
+func (t *sqlTracer) CommentTags(ctx context.Context) map[string]string {
+	if !t.comment {
+		return nil
+	}
+
+	tags := map[string]string{"application": t.application}
+	propagation.TraceContext{}.Inject(ctx, propagation.MapCarrier(tags))
+	return tags
+}
+
+type sqlSpan struct {
+	span trace.Span
+}
+
+func (s *sqlSpan) SetRows(n int64) {
+	s.span.SetAttributes(attribute.Int64("db.rows", n))
+}
+
+func (s *sqlSpan) End(err error) {
+	if err != nil && err != driver.ErrSkip {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,122 @@
+package otel
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/rfyiamcool/go-tracer/internal/sqlhook/sqltest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWrapDriver(t *testing.T) {
+	recorder := newTestRecorder()
+
+	fake := &sqltest.Driver{Rows: [][]driver.Value{{int64(1), "bob"}}}
+	sql.Register("sqltest-otel", WrapDriver(fake, WithSQLArgs(), WithSQLSystem(semconv.DBSystemMySQL)))
+	db, err := sql.Open("sqltest-otel", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+
+	var name string
+	err = db.QueryRowContext(ctx, "select id, name from user where id = ?", 1).Scan(new(int64), &name)
+	assert.Nil(t, err)
+	assert.Equal(t, "bob", name)
+
+	stmt, err := db.PrepareContext(ctx, "delete from user where name = 'error'")
+	assert.Nil(t, err)
+	_, err = stmt.ExecContext(ctx)
+	assert.Equal(t, sqltest.ErrFake, err)
+	stmt.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit())
+	tx, err = db.BeginTx(ctx, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Rollback())
+	parent.End()
+
+	spans := recorder.Ended()
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	}
+	assert.Equal(t, []string{"sql.connect", "sql.query", "sql.rows", "sql.prepare", "sql.exec", "sql.begin", "sql.commit", "sql.begin", "sql.rollback", "parent"}, names)
+
+	// the commit and rollback spans are siblings of the begin span.
+	for _, span := range spans[5:9] {
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID(), span.Name())
+	}
+
+	query, exec := spans[1], spans[4]
+	assert.Contains(t, query.Attributes(), semconv.DBSystemMySQL)
+	assert.Contains(t, query.Attributes(), semconv.DBStatementKey.String("select id, name from user where id = ?"))
+	assert.Equal(t, codes.Error, exec.Status().Code)
+}
+
+func TestWrapDriverComment(t *testing.T) {
+	newTestRecorder()
+
+	fake := &sqltest.Driver{}
+	sql.Register("sqltest-otel-comment", WrapDriver(fake, WithSQLComment("api")))
+	db, err := sql.Open("sqltest-otel-comment", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "parent")
+	defer span.End()
+
+	_, err = db.ExecContext(ContextWithSQLRoute(ctx, "/user"), "delete from user")
+	assert.Nil(t, err)
+	_, err = db.ExecContext(ContextWithoutSQLComment(ctx), "delete from user")
+	assert.Nil(t, err)
+
+	queries := fake.Queries()
+	assert.Len(t, queries, 2)
+	assert.Regexp(t, `^delete from user /\*application='api',route='%2Fuser',traceparent='00-[0-9a-f]{32}-[0-9a-f]{16}-01'\*/$`, queries[0])
+	assert.Contains(t, queries[0], span.SpanContext().TraceID().String())
+	assert.Equal(t, "delete from user", queries[1])
+}
+
+func TestWrapDriverCommentPrepare(t *testing.T) {
+	recorder := newTestRecorder()
+
+	fake := &sqltest.Driver{}
+	sql.Register("sqltest-otel-comment-prepare", WrapDriver(fake, WithSQLComment("api")))
+	db, err := sql.Open("sqltest-otel-comment-prepare", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	ctx1, span1 := otel.Tracer("test").Start(context.Background(), "first")
+	defer span1.End()
+	ctx2, span2 := otel.Tracer("test").Start(context.Background(), "second")
+	defer span2.End()
+
+	stmt, err := db.PrepareContext(ctx1, "delete from user")
+	assert.Nil(t, err)
+	defer stmt.Close()
+	_, err = stmt.ExecContext(ctx1)
+	assert.Nil(t, err)
+	_, err = stmt.ExecContext(ctx2)
+	assert.Nil(t, err)
+
+	// the prepared statement doesn't carry the traceparent of the first caller.
+	assert.Equal(t, []string{"delete from user /*application='api'*/", "delete from user /*application='api'*/"}, fake.Queries())
+
+	var parents []trace.SpanID
+	for _, span := range recorder.Ended() {
+		if span.Name() == "sql.exec" {
+			parents = append(parents, span.Parent().SpanID())
+		}
+	}
+	assert.Equal(t, []trace.SpanID{span1.SpanContext().SpanID(), span2.SpanContext().SpanID()}, parents)
+}
@@ -0,0 +1,40 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/rfyiamcool/go-tracer/internal/stackutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace start the child span of ctx named by the caller function, with code.function, code.filepath
+// and code.lineno attributes, end records the error of errp and ends the span, errp can be nil.
+//
+//	func (s *Service) GetUser(ctx context.Context, id int) (user *User, err error) {
+//		ctx, end := otel.Trace(ctx, attribute.Int("user.id", id))
+//		defer end(&err)
+//		...
+//	}
+func Trace(ctx context.Context, attrs ...attribute.KeyValue) (context.Context, func(errp *error)) {
+	si := stackutil.Caller(1)
+
+	ctx, span := otel.GetTracerProvider().Tracer("").Start(ctx, si.Function,
+		trace.WithAttributes(
+			semconv.CodeFunctionKey.String(si.Function),
+			semconv.CodeFilepathKey.String(si.Frame.File),
+			semconv.CodeLineNumberKey.Int(si.Line),
+		),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx, func(errp *error) {
+		if errp != nil && *errp != nil {
+			span.RecordError(*errp)
+			span.SetStatus(codes.Error, (*errp).Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,47 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+func traceUser(ctx context.Context, id int) (err error) {
+	ctx, end := Trace(ctx, attribute.Int("user.id", id))
+	defer end(&err)
+
+	if id == 0 {
+		return errors.New("not found")
+	}
+	return nil
+}
+
+func TestTrace(t *testing.T) {
+	recorder := newTestRecorder()
+
+	assert.Nil(t, traceUser(context.Background(), 1))
+	assert.NotNil(t, traceUser(context.Background(), 0))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	for _, span := range spans {
+		assert.Equal(t, "traceUser", span.Name())
+
+		attrs := make(map[attribute.Key]attribute.Value)
+		for _, kv := range span.Attributes() {
+			attrs[kv.Key] = kv.Value
+		}
+		assert.Equal(t, "traceUser", attrs[semconv.CodeFunctionKey].AsString())
+		assert.Contains(t, attrs[semconv.CodeFilepathKey].AsString(), "otel/trace_test.go")
+		assert.Equal(t, int64(15), attrs[semconv.CodeLineNumberKey].AsInt64())
+	}
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "not found", spans[1].Status().Description)
+}
@@ -0,0 +1,166 @@
+// Package zaptrace correlate the zap logs with the opentelemetry spans, add trace_id and span_id of
+// the context to every log entry, and mirror the logs to the span events optionally.
+package zaptrace
+
+import (
+	"context"
+
+	"github.com/rfyiamcool/go-tracer/internal/logutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Fields returns trace_id and span_id fields of the span in ctx, returns nil if ctx has no valid span.
+//
+//	logger.Info("get user", zaptrace.Fields(ctx)...)
+func Fields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zap.Field{zap.String(logutil.TraceIDKey, sc.TraceID().String()), zap.String(logutil.SpanIDKey, sc.SpanID().String())}
+}
+
+// Context returns the field which carries ctx to the core of NewCore, the field is skipped by
+// the encoder of zap.
+//
+//	logger.Warn("slow query", zaptrace.Context(ctx), zap.Duration("cost", cost))
+func Context(ctx context.Context) zap.Field {
+	return zap.Field{Key: "context", Type: zapcore.SkipType, Interface: ctx}
+}
+
+type core struct {
+	zapcore.Core
+
+	spanEvent bool
+	spanLevel zapcore.Level
+	ctx       context.Context
+}
+
+type Option func(*core)
+
+// WithSpanEvent mirror the entries at level or above to the span events of ctx, like zap.WarnLevel.
+func WithSpanEvent(level zapcore.Level) Option {
+	return func(c *core) {
+		c.spanEvent = true
+		c.spanLevel = level
+	}
+}
+
+// NewCore wraps the core, the entry with Context field is added trace_id and span_id fields.
+//
+//	logger := zap.New(zaptrace.NewCore(core, zaptrace.WithSpanEvent(zap.WarnLevel)))
+//	logger.Info("get user", zaptrace.Context(ctx), zap.Int("id", id))
+func NewCore(c zapcore.Core, opts ...Option) zapcore.Core {
+	wrapped := &core{Core: c}
+	for _, opt := range opts {
+		opt(wrapped)
+	}
+	return wrapped
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	ctx, fields := c.extract(fields)
+	clone := *c
+	clone.Core = c.Core.With(fields)
+	if ctx != nil {
+		clone.ctx = ctx
+	}
+	return &clone
+}
+
+// Check asks the inner core, so the sampler and the cores of zapcore.NewTee decide for
+// themselves, the entry is written to the inner cores which accept it.
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	inner := c.Core.Check(ent, nil)
+	if inner == nil {
+		return ce
+	}
+	checked := &checkedCore{core: c, inner: inner}
+	checked.outer = ce.AddCore(ent, checked)
+	return checked.outer
+}
+
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.trace(ent, fields))
+}
+
+// trace returns the fields with trace_id and span_id, and mirrors the entry to the span.
+func (c *core) trace(ent zapcore.Entry, fields []zapcore.Field) []zapcore.Field {
+	ctx, fields := c.extract(fields)
+	if ctx == nil {
+		ctx = c.ctx
+	}
+	if ctx == nil {
+		return fields
+	}
+	// the trace fields of With are added here too, so the per-call Context replaces them.
+	fields = append(fields[:len(fields):len(fields)], Fields(ctx)...)
+
+	if c.spanEvent && ent.Level >= c.spanLevel {
+		if span := trace.SpanFromContext(ctx); span.IsRecording() {
+			addEvent(span, ent, fields)
+		}
+	}
+	return fields
+}
+
+// checkedCore writes the entry to the inner cores which accept it in Check.
+type checkedCore struct {
+	*core
+	inner *zapcore.CheckedEntry
+	outer *zapcore.CheckedEntry
+}
+
+// Write reports the errors of the inner cores to the ErrorOutput of logger, which is set to the
+// outer entry after Check, so nil is returned to not report them twice.
+func (c *checkedCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.inner.ErrorOutput = c.outer.ErrorOutput
+	c.inner.Write(c.trace(ent, fields)...)
+	return nil
+}
+
+// extract returns the ctx of Context field, and the fields without it.
+func (c *core) extract(fields []zapcore.Field) (context.Context, []zapcore.Field) {
+	var ctx context.Context
+	for _, f := range fields {
+		if f.Type != zapcore.SkipType {
+			continue
+		}
+		if fctx, ok := f.Interface.(context.Context); ok {
+			ctx = fctx
+		}
+	}
+	if ctx == nil {
+		return nil, fields
+	}
+
+	out := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		if _, ok := f.Interface.(context.Context); !ok || f.Type != zapcore.SkipType {
+			out = append(out, f)
+		}
+	}
+	return ctx, out
+}
+
+func addEvent(span trace.Span, ent zapcore.Entry, fields []zapcore.Field) {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String(logutil.LevelKey, ent.Level.String()),
+		attribute.String(logutil.MessageKey, ent.Message),
+	}
+	for _, k := range logutil.SortedKeys(enc.Fields) {
+		if k == logutil.TraceIDKey || k == logutil.SpanIDKey {
+			continue
+		}
+		attrs = append(attrs, logutil.Attribute(k, enc.Fields[k]))
+	}
+	span.AddEvent(logutil.EventLog, trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,149 @@
+package zaptrace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCore(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "get-user")
+	sc := span.SpanContext()
+
+	obs, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(NewCore(obs, WithSpanEvent(zap.WarnLevel)))
+
+	logger.Info("get user", Context(ctx), zap.Int("id", 1))
+	logger.With(Context(ctx)).Warn("slow query", zap.Int("cost", 300))
+	logger.Warn("no context")
+	logger.Info("fields", Fields(ctx)...)
+	span.End()
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 4)
+	for _, i := range []int{0, 1, 3} {
+		fields := entries[i].ContextMap()
+		assert.Equal(t, sc.TraceID().String(), fields["trace_id"])
+		assert.Equal(t, sc.SpanID().String(), fields["span_id"])
+		assert.Nil(t, fields["context"])
+	}
+	assert.Equal(t, int64(1), entries[0].ContextMap()["id"])
+	assert.Empty(t, entries[2].ContextMap())
+
+	// only the warn log with context is mirrored.
+	events := recorder.Ended()[0].Events()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "log", events[0].Name)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("log.severity", "warn"),
+		attribute.String("log.message", "slow query"),
+		attribute.Int64("cost", 300),
+	}, events[0].Attributes)
+}
+
+func TestCoreSampler(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "get-user")
+
+	obs, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(NewCore(zapcore.NewSamplerWithOptions(obs, time.Second, 1, 0), WithSpanEvent(zap.InfoLevel)))
+	for i := 0; i < 3; i++ {
+		logger.Info("get user", Context(ctx))
+	}
+	span.End()
+
+	// the dropped entries of sampler are neither written nor added to the span.
+	assert.Equal(t, 1, logs.Len())
+	assert.NotNil(t, logs.All()[0].ContextMap()["trace_id"])
+	assert.Len(t, recorder.Ended()[0].Events(), 1)
+}
+
+func TestCoreTee(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "get-user")
+	defer span.End()
+
+	infoObs, infoLogs := observer.New(zap.InfoLevel)
+	warnObs, warnLogs := observer.New(zap.WarnLevel)
+	logger := zap.New(NewCore(zapcore.NewTee(infoObs, warnObs)))
+
+	logger.Info("get user", Context(ctx))
+	logger.Warn("slow query", Context(ctx))
+
+	assert.Equal(t, 2, infoLogs.Len())
+	assert.Equal(t, 1, warnLogs.Len())
+	entry := warnLogs.All()[0]
+	assert.Equal(t, "slow query", entry.Message)
+	assert.NotNil(t, entry.ContextMap()["trace_id"])
+}
+
+func TestCoreContextOverride(t *testing.T) {
+	provider := tracesdk.NewTracerProvider()
+	parentCtx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+	childCtx, child := provider.Tracer("test").Start(parentCtx, "child")
+	defer child.End()
+
+	obs, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(NewCore(obs)).With(Context(parentCtx))
+	logger.Info("get user", Context(childCtx))
+	logger.Info("list users")
+
+	// the Context of call replaces the one of With, trace_id and span_id are added once.
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, []string{"trace_id", "span_id"}, fieldKeys(entries[0]))
+	assert.Equal(t, child.SpanContext().SpanID().String(), entries[0].ContextMap()["span_id"])
+	assert.Equal(t, []string{"trace_id", "span_id"}, fieldKeys(entries[1]))
+	assert.Equal(t, parent.SpanContext().SpanID().String(), entries[1].ContextMap()["span_id"])
+}
+
+func fieldKeys(entry observer.LoggedEntry) []string {
+	var keys []string
+	for _, f := range entry.Context {
+		keys = append(keys, f.Key)
+	}
+	return keys
+}
+
+type errorWriter struct{}
+
+func (errorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func (errorWriter) Sync() error {
+	return nil
+}
+
+func TestCoreErrorOutput(t *testing.T) {
+	inner := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), errorWriter{}, zap.InfoLevel)
+	output := &bytes.Buffer{}
+	logger := zap.New(NewCore(inner), zap.ErrorOutput(zapcore.AddSync(output)))
+
+	// the write errors of inner core are reported to the ErrorOutput of logger once.
+	logger.Info("get user")
+	assert.Equal(t, 1, strings.Count(output.String(), "write error: disk full"))
+}
+
+func TestFieldsWithoutSpan(t *testing.T) {
+	assert.Nil(t, Fields(context.Background()))
+}
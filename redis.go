@@ -3,43 +3,109 @@ package tracer
 import (
 	"context"
 	"strconv"
+	"strings"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/rfyiamcool/go-tracer/internal/redisutil"
 )
 
 type RedisTracingHook struct {
 	tracer opentracing.Tracer
+
+	formatter    redisutil.Formatter
+	skipCommands map[string]bool
+	tags         opentracing.Tags
 }
 
 var _ redis.Hook = RedisTracingHook{}
 
+type RedisOption func(*RedisTracingHook)
+
+// WithRedisSanitize only log the command name and key, drop the values which are often large blobs or secrets.
+func WithRedisSanitize() RedisOption {
+	return func(hook *RedisTracingHook) {
+		hook.formatter.Sanitize = true
+	}
+}
+
+// WithRedisMaxArgLength truncate the arg longer than size in statement.
+func WithRedisMaxArgLength(size int) RedisOption {
+	return func(hook *RedisTracingHook) {
+		hook.formatter.MaxArgLength = size
+	}
+}
+
+// WithRedisSkipCommands don't trace the noisy commands, like ping.
+func WithRedisSkipCommands(names ...string) RedisOption {
+	return func(hook *RedisTracingHook) {
+		for _, name := range names {
+			hook.skipCommands[strings.ToLower(name)] = true
+		}
+	}
+}
+
+// WithRedisOptions tag peer address and db index by the options of redis client.
+func WithRedisOptions(opt *redis.Options) RedisOption {
+	return func(hook *RedisTracingHook) {
+		hook.tags[string(ext.PeerAddress)] = opt.Addr
+		hook.tags[string(ext.DBInstance)] = strconv.Itoa(opt.DB)
+	}
+}
+
+// WithRedisClusterOptions tag the seed node addresses as db.redis.cluster_nodes, the peer of command is unknown
+// until the cluster client routes it.
+func WithRedisClusterOptions(opt *redis.ClusterOptions) RedisOption {
+	return func(hook *RedisTracingHook) {
+		hook.tags["db.redis.cluster_nodes"] = strings.Join(opt.Addrs, ",")
+		hook.tags["db.redis.cluster"] = true
+	}
+}
+
 // NewHook creates a new go-redis hook instance and that will collect spans using the provided tracer.
-func NewRedisHook(tracer opentracing.Tracer) redis.Hook {
-	return &RedisTracingHook{
-		tracer: tracer,
+func NewRedisHook(tracer opentracing.Tracer, opts ...RedisOption) redis.Hook {
+	hook := &RedisTracingHook{
+		tracer:       tracer,
+		skipCommands: make(map[string]bool),
+		tags:         opentracing.Tags{string(ext.DBType): "redis"},
 	}
+	for _, opt := range opts {
+		opt(hook)
+	}
+	return hook
 }
 
 func (hook RedisTracingHook) createSpan(ctx context.Context, operationName string) (opentracing.Span, context.Context) {
 	span := opentracing.SpanFromContext(ctx)
 	if span != nil {
-		childSpan := hook.tracer.StartSpan(operationName, opentracing.ChildOf(span.Context()))
+		childSpan := hook.tracer.StartSpan(operationName, opentracing.ChildOf(span.Context()), hook.tags)
 		return childSpan, opentracing.ContextWithSpan(ctx, childSpan)
 	}
 
-	return opentracing.StartSpanFromContextWithTracer(ctx, hook.tracer, operationName)
+	return opentracing.StartSpanFromContextWithTracer(ctx, hook.tracer, operationName, hook.tags)
+}
+
+func (hook RedisTracingHook) skip(cmd redis.Cmder) bool {
+	return hook.skipCommands[cmd.Name()]
 }
 
 func (hook RedisTracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	if hook.skip(cmd) {
+		return ctx, nil
+	}
+
 	span, ctx := hook.createSpan(ctx, cmd.FullName())
-	span.LogKV("redis.cmd.args", cmd.String())
-	span.SetTag("db.type", "redis")
+	span.LogKV("redis.cmd.args", hook.formatter.Statement(cmd.Args()))
 	return ctx, nil
 }
 
 func (hook RedisTracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	if hook.skip(cmd) {
+		return nil
+	}
+
 	span := opentracing.SpanFromContext(ctx)
 	defer span.Finish()
 
@@ -51,7 +117,6 @@ func (hook RedisTracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder)
 
 func (hook RedisTracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
 	span, ctx := hook.createSpan(ctx, "pipeline")
-	span.SetTag("db.type", "redis")
 	span.SetTag("db.redis.num_cmd", len(cmds))
 	return ctx, nil
 }
@@ -61,9 +126,16 @@ func (hook RedisTracingHook) AfterProcessPipeline(ctx context.Context, cmds []re
 	defer span.Finish()
 
 	for i, cmd := range cmds {
+		fields := []log.Field{
+			log.String("event", cmd.FullName()),
+			log.String("redis.cmd.args", hook.formatter.Statement(cmd.Args())),
+		}
+
 		if err := cmd.Err(); err != nil {
 			hook.recordError(ctx, "db.error"+strconv.Itoa(i), span, err)
+			fields = append(fields, log.String("db.error", err.Error()))
 		}
+		span.LogFields(fields...)
 	}
 	return nil
 }
@@ -0,0 +1,60 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisHook(t *testing.T) {
+	mtracer := mocktracer.New()
+	hook := NewRedisHook(mtracer,
+		WithRedisSanitize(),
+		WithRedisSkipCommands("PING"),
+		WithRedisOptions(&redis.Options{Addr: "127.0.0.1:6379", DB: 2}),
+	)
+	ctx := context.Background()
+
+	for _, cmd := range []redis.Cmder{redis.NewStatusCmd(ctx, "ping"), redis.NewStatusCmd(ctx, "set", "key", "secret")} {
+		cctx, _ := hook.BeforeProcess(ctx, cmd)
+		hook.AfterProcess(cctx, cmd)
+	}
+
+	cmds := []redis.Cmder{redis.NewStringCmd(ctx, "get", "key"), redis.NewStringCmd(ctx, "get", "key2")}
+	cmds[1].SetErr(errors.New("timeout"))
+	cctx, _ := hook.BeforeProcessPipeline(ctx, cmds)
+	hook.AfterProcessPipeline(cctx, cmds)
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 2)
+
+	assert.Equal(t, "set", spans[0].OperationName)
+	assert.Equal(t, "127.0.0.1:6379", spans[0].Tag("peer.address"))
+	assert.Equal(t, "2", spans[0].Tag("db.instance"))
+	assert.Equal(t, "set key", spans[0].Logs()[0].Fields[0].ValueString)
+
+	assert.Equal(t, "pipeline", spans[1].OperationName)
+	assert.Equal(t, true, spans[1].Tag("error"))
+	assert.Equal(t, "timeout", spans[1].Tag("db.error1"))
+	assert.Len(t, spans[1].Logs(), 2)
+}
+
+func TestRedisHookCluster(t *testing.T) {
+	mtracer := mocktracer.New()
+	hook := NewRedisHook(mtracer, WithRedisClusterOptions(&redis.ClusterOptions{Addrs: []string{"10.0.0.1:6379", "10.0.0.2:6379"}}))
+	ctx := context.Background()
+
+	cmd := redis.NewStringCmd(ctx, "get", "key")
+	cctx, _ := hook.BeforeProcess(ctx, cmd)
+	hook.AfterProcess(cctx, cmd)
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "10.0.0.1:6379,10.0.0.2:6379", spans[0].Tag("db.redis.cluster_nodes"))
+	assert.Equal(t, true, spans[0].Tag("db.redis.cluster"))
+	assert.Nil(t, spans[0].Tag("peer.address"))
+}
@@ -0,0 +1,161 @@
+// Package redisv9 trace go-redis v9 by opentracing, the hook of go-redis v8 is tracer.NewRedisHook.
+package redisv9
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/redis/go-redis/v9"
+	"github.com/rfyiamcool/go-tracer/internal/redisutil"
+)
+
+type TracingHook struct {
+	tracer opentracing.Tracer
+
+	formatter    redisutil.Formatter
+	skipCommands map[string]bool
+	tags         opentracing.Tags
+}
+
+var _ redis.Hook = &TracingHook{}
+
+type Option func(*TracingHook)
+
+// WithSanitize only log the command name and key, drop the values which are often large blobs or secrets.
+func WithSanitize() Option {
+	return func(hook *TracingHook) {
+		hook.formatter.Sanitize = true
+	}
+}
+
+// WithMaxArgLength truncate the arg longer than size in statement.
+func WithMaxArgLength(size int) Option {
+	return func(hook *TracingHook) {
+		hook.formatter.MaxArgLength = size
+	}
+}
+
+// WithSkipCommands don't trace the noisy commands, like ping.
+func WithSkipCommands(names ...string) Option {
+	return func(hook *TracingHook) {
+		for _, name := range names {
+			hook.skipCommands[strings.ToLower(name)] = true
+		}
+	}
+}
+
+// WithOptions tag peer address and db index by the options of redis client.
+func WithOptions(opt *redis.Options) Option {
+	return func(hook *TracingHook) {
+		hook.tags[string(ext.PeerAddress)] = opt.Addr
+		hook.tags[string(ext.DBInstance)] = strconv.Itoa(opt.DB)
+	}
+}
+
+// WithClusterOptions tag the seed node addresses as db.redis.cluster_nodes, the peer of command is unknown
+// until the cluster client routes it.
+func WithClusterOptions(opt *redis.ClusterOptions) Option {
+	return func(hook *TracingHook) {
+		hook.tags["db.redis.cluster_nodes"] = strings.Join(opt.Addrs, ",")
+		hook.tags["db.redis.cluster"] = true
+	}
+}
+
+// NewHook creates a new go-redis v9 hook which collect spans using the provided tracer,
+// use the global tracer when tracer is nil.
+//
+//	rdb := redis.NewClient(opt)
+//	rdb.AddHook(redisv9.NewHook(tracer.GetTracer(), redisv9.WithOptions(opt)))
+func NewHook(tracer opentracing.Tracer, opts ...Option) *TracingHook {
+	if tracer == nil {
+		tracer = opentracing.GlobalTracer()
+	}
+
+	hook := &TracingHook{
+		tracer:       tracer,
+		skipCommands: make(map[string]bool),
+		tags:         opentracing.Tags{string(ext.DBType): "redis"},
+	}
+	for _, opt := range opts {
+		opt(hook)
+	}
+	return hook
+}
+
+func (hook *TracingHook) createSpan(ctx context.Context, operationName string) (opentracing.Span, context.Context) {
+	return opentracing.StartSpanFromContextWithTracer(ctx, hook.tracer, operationName, hook.tags)
+}
+
+// DialHook trace the connection establishment.
+func (hook *TracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		span, ctx := hook.createSpan(ctx, "redis.dial")
+		defer span.Finish()
+
+		span.SetTag("net.network", network)
+		ext.PeerAddress.Set(span, addr)
+
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			recordError(span, "db.error", err)
+		}
+		return conn, err
+	}
+}
+
+// ProcessHook trace the command.
+func (hook *TracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if hook.skipCommands[cmd.Name()] {
+			return next(ctx, cmd)
+		}
+
+		span, ctx := hook.createSpan(ctx, cmd.FullName())
+		defer span.Finish()
+
+		span.LogKV("redis.cmd.args", hook.formatter.Statement(cmd.Args()))
+
+		err := next(ctx, cmd)
+		if err != nil {
+			recordError(span, "db.error", err)
+		}
+		return err
+	}
+}
+
+// ProcessPipelineHook trace the pipeline, each command is logged as sub-event.
+func (hook *TracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		span, ctx := hook.createSpan(ctx, "pipeline")
+		defer span.Finish()
+
+		span.SetTag("db.redis.num_cmd", len(cmds))
+
+		err := next(ctx, cmds)
+		for i, cmd := range cmds {
+			fields := []log.Field{
+				log.String("event", cmd.FullName()),
+				log.String("redis.cmd.args", hook.formatter.Statement(cmd.Args())),
+			}
+
+			if err := cmd.Err(); err != nil {
+				recordError(span, "db.error"+strconv.Itoa(i), err)
+				fields = append(fields, log.String("db.error", err.Error()))
+			}
+			span.LogFields(fields...)
+		}
+		return err
+	}
+}
+
+func recordError(span opentracing.Span, errorTag string, err error) {
+	if err != redis.Nil {
+		span.SetTag(string(ext.Error), true)
+		span.SetTag(errorTag, err.Error())
+	}
+}
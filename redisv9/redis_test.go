@@ -0,0 +1,65 @@
+package redisv9
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHook(t *testing.T) {
+	mtracer := mocktracer.New()
+
+	opt := &redis.Options{
+		Addr:       "127.0.0.1:6379",
+		MaxRetries: -1,
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	rdb := redis.NewClient(opt)
+	defer rdb.Close()
+	rdb.AddHook(NewHook(mtracer, WithOptions(opt), WithSanitize(), WithSkipCommands("ping")))
+
+	ctx := context.Background()
+	assert.NotNil(t, rdb.Ping(ctx).Err())
+	assert.NotNil(t, rdb.Set(ctx, "key", "secret", 0).Err())
+
+	var dials, sets int
+	for _, span := range mtracer.FinishedSpans() {
+		switch span.OperationName {
+		case "redis.dial":
+			dials++
+			assert.Equal(t, true, span.Tag("error"))
+		case "set":
+			sets++
+			assert.Equal(t, true, span.Tag("error"))
+			assert.Equal(t, "set key", span.Logs()[0].Fields[0].ValueString)
+		default:
+			t.Errorf("unexpected span %s", span.OperationName)
+		}
+	}
+	assert.True(t, dials > 0)
+	assert.Equal(t, 1, sets)
+}
+
+func TestClusterOptions(t *testing.T) {
+	mtracer := mocktracer.New()
+	hook := NewHook(mtracer, WithClusterOptions(&redis.ClusterOptions{Addrs: []string{"10.0.0.1:6379", "10.0.0.2:6379"}}))
+
+	ctx := context.Background()
+	process := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		return nil
+	})
+	assert.Nil(t, process(ctx, redis.NewStringCmd(ctx, "get", "key")))
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "10.0.0.1:6379,10.0.0.2:6379", spans[0].Tag("db.redis.cluster_nodes"))
+	assert.Equal(t, true, spans[0].Tag("db.redis.cluster"))
+	assert.Nil(t, spans[0].Tag("peer.address"))
+}
@@ -0,0 +1,152 @@
+package tracer
+
+import (
+	"context"
+	"database/sql/driver"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/rfyiamcool/go-tracer/internal/jaegerutil"
+	"github.com/rfyiamcool/go-tracer/internal/sqlhook"
+	"github.com/uber/jaeger-client-go"
+)
+
+type sqlTracer struct {
+	formatter sqlhook.Formatter
+	tags      opentracing.Tags
+
+	comment     bool
+	application string
+	xTraceID    bool
+}
+
+// SQLOption database/sql driver wrapper option
+type SQLOption func(*sqlTracer)
+
+// WithSQLArgs tag the args of query with db.args, the args are not recorded by default.
+func WithSQLArgs() SQLOption {
+	return func(t *sqlTracer) {
+		t.formatter.Args = true
+	}
+}
+
+// WithSQLRedact replace the literals of db.statement and the values of db.args with `?`.
+func WithSQLRedact() SQLOption {
+	return func(t *sqlTracer) {
+		t.formatter.Redact = true
+	}
+}
+
+// WithSQLDBType tag db.type, like mysql, postgres. default: sql
+func WithSQLDBType(name string) SQLOption {
+	return func(t *sqlTracer) {
+		t.tags[string(ext.DBType)] = name
+	}
+}
+
+// WithSQLInstance tag db.instance by the database name.
+func WithSQLInstance(name string) SQLOption {
+	return func(t *sqlTracer) {
+		t.tags[string(ext.DBInstance)] = name
+	}
+}
+
+// WithSQLComment append sqlcommenter comment with traceparent, route and application to the statement,
+// like `select 1 /*application='api',traceparent='00-...-01'*/`. the route is set by ContextWithSQLRoute.
+func WithSQLComment(application string) SQLOption {
+	return func(t *sqlTracer) {
+		t.comment = true
+		t.application = application
+	}
+}
+
+// WithSQLCommentXTraceID comment x-trace-id of GetXTraceID instead of traceparent.
+func WithSQLCommentXTraceID() SQLOption {
+	return func(t *sqlTracer) {
+		t.xTraceID = true
+	}
+}
+
+// ContextWithSQLRoute set the route of sql comment, like `/api/user/:id`.
+func ContextWithSQLRoute(ctx context.Context, route string) context.Context {
+	return sqlhook.ContextWithRoute(ctx, route)
+}
+
+// ContextWithoutSQLComment don't comment the statements with the context, the comment makes
+// the server-side cache of prepared statements useless. the prepared statements are commented
+// with the application only, the traceparent and route of the preparing caller would be carried
+// by all the later calls of the statement.
+func ContextWithoutSQLComment(ctx context.Context) context.Context {
+	return sqlhook.ContextWithoutComment(ctx)
+}
+
+func newSQLTracer(opts []SQLOption) *sqlTracer {
+	t := &sqlTracer{
+		tags: opentracing.Tags{string(ext.DBType): "sql"},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WrapDriver return a driver which creates spans for the query, exec, prepare, transaction and rows,
+// the spans are children of the span in the context.
+//
+//	sql.Register("mysql-trace", tracer.WrapDriver(&mysql.MySQLDriver{}, tracer.WithSQLDBType("mysql")))
+//	db, err := sql.Open("mysql-trace", dsn)
+func WrapDriver(d driver.Driver, opts ...SQLOption) driver.Driver {
+	return sqlhook.WrapDriver(d, newSQLTracer(opts))
+}
+
+// WrapConnector return a connector for sql.OpenDB, like WrapDriver.
+func WrapConnector(c driver.Connector, opts ...SQLOption) driver.Connector {
+	return sqlhook.WrapConnector(c, newSQLTracer(opts))
+}
+
+func (t *sqlTracer) Start(ctx context.Context, op string, query string, args []driver.NamedValue) (context.Context, sqlhook.Span) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, op, t.tags, ext.SpanKindRPCClient)
+	if query != "" {
+		ext.DBStatement.Set(span, t.formatter.Statement(query))
+	}
+	if s := t.formatter.FormatArgs(args); s != "" {
+		span.SetTag("db.args", s)
+	}
+	return ctx, &sqlSpan{span: span}
+}
+
+func (t *sqlTracer) CommentTags(ctx context.Context) map[string]string {
+	if !t.comment {
+		return nil
+	}
+
+	tags := map[string]string{"application": t.application}
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return tags
+	}
+
+	if t.xTraceID {
+		tags[HeaderXTraceID] = GetXTraceID(span)
+	} else if sc, ok := span.Context().(jaeger.SpanContext); ok {
+		tags[jaegerutil.HeaderTraceparent] = jaegerutil.Traceparent(sc)
+	}
+	return tags
+}
+
+type sqlSpan struct {
+	span opentracing.Span
+}
+
+func (s *sqlSpan) SetRows(n int64) {
+	s.span.SetTag("db.rows", n)
+}
+
+func (s *sqlSpan) End(err error) {
+	if err != nil && err != driver.ErrSkip {
+		ext.Error.Set(s.span, true)
+		s.span.LogFields(log.String("event", "error"), log.String("message", err.Error()))
+	}
+	s.span.Finish()
+}
@@ -0,0 +1,121 @@
+package tracer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/rfyiamcool/go-tracer/internal/sqlhook/sqltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestWrapDriver(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	fake := &sqltest.Driver{Rows: [][]driver.Value{{int64(1), "bob"}, {int64(2), "alice"}}}
+	sql.Register("sqltest-tracer", WrapDriver(fake, WithSQLArgs(), WithSQLRedact(), WithSQLDBType("mysql")))
+	db, err := sql.Open("sqltest-tracer", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	parent, ctx := StartSpanFromContext(context.Background(), "parent")
+
+	rows, err := db.QueryContext(ctx, "select id, name from user where age > 10 and id = ?", 1)
+	assert.Nil(t, err)
+	for rows.Next() {
+	}
+	rows.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	assert.Nil(t, err)
+	_, err = tx.ExecContext(ctx, "update user set name = 'error'")
+	assert.NotNil(t, err)
+	assert.Nil(t, tx.Rollback())
+
+	tx, err = db.BeginTx(ctx, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit())
+	parent.Finish()
+
+	spans := mtracer.FinishedSpans()
+	ops := make([]string, 0, len(spans))
+	for _, span := range spans {
+		ops = append(ops, span.OperationName)
+		if span.OperationName != "parent" {
+			assert.Equal(t, "mysql", span.Tag("db.type"))
+			assert.Equal(t, parent.(*mocktracer.MockSpan).SpanContext.TraceID, span.SpanContext.TraceID)
+		}
+	}
+	assert.Equal(t, []string{"sql.connect", "sql.query", "sql.rows", "sql.begin", "sql.exec", "sql.rollback", "sql.begin", "sql.commit", "parent"}, ops)
+
+	// the commit and rollback spans are siblings of the begin span.
+	parentID := parent.(*mocktracer.MockSpan).SpanContext.SpanID
+	for _, i := range []int{3, 5, 6, 7} {
+		assert.Equal(t, parentID, spans[i].ParentID, spans[i].OperationName)
+	}
+
+	query, rowsSpan, exec := spans[1], spans[2], spans[4]
+	assert.Equal(t, "select id, name from user where age > ? and id = ?", query.Tag("db.statement"))
+	assert.Equal(t, "[?]", query.Tag("db.args"))
+	assert.Equal(t, int64(2), rowsSpan.Tag("db.rows"))
+	assert.Equal(t, "update user set name = ?", exec.Tag("db.statement"))
+	assert.Equal(t, true, exec.Tag("error"))
+}
+
+func TestWrapDriverComment(t *testing.T) {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+	SeteTracer(jtracer)
+
+	fake := &sqltest.Driver{}
+	sql.Register("sqltest-tracer-comment", WrapDriver(fake, WithSQLComment("api")))
+	db, err := sql.Open("sqltest-tracer-comment", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	span, ctx := StartSpanFromContext(context.Background(), "parent")
+	defer span.Finish()
+
+	_, err = db.ExecContext(ContextWithSQLRoute(ctx, "/user"), "delete from user")
+	assert.Nil(t, err)
+	_, err = db.ExecContext(ContextWithoutSQLComment(ctx), "delete from user")
+	assert.Nil(t, err)
+
+	queries := fake.Queries()
+	assert.Len(t, queries, 2)
+	assert.Regexp(t, `^delete from user /\*application='api',route='%2Fuser',traceparent='00-[0-9a-f]{32}-[0-9a-f]{16}-01'\*/$`, queries[0])
+	assert.Contains(t, queries[0], GetTraceIDFromCtx(ctx))
+	assert.Equal(t, "delete from user", queries[1])
+}
+
+func TestWrapDriverCommentPrepare(t *testing.T) {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+	SeteTracer(jtracer)
+
+	fake := &sqltest.Driver{}
+	sql.Register("sqltest-tracer-comment-prepare", WrapDriver(fake, WithSQLComment("api")))
+	db, err := sql.Open("sqltest-tracer-comment-prepare", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	span1, ctx1 := StartSpanFromContext(context.Background(), "first")
+	defer span1.Finish()
+	span2, ctx2 := StartSpanFromContext(context.Background(), "second")
+	defer span2.Finish()
+
+	stmt, err := db.PrepareContext(ContextWithSQLRoute(ctx1, "/user"), "delete from user")
+	assert.Nil(t, err)
+	defer stmt.Close()
+	_, err = stmt.ExecContext(ctx1)
+	assert.Nil(t, err)
+	_, err = stmt.ExecContext(ctx2)
+	assert.Nil(t, err)
+
+	// the prepared statement doesn't carry the traceparent and route of the first caller.
+	assert.Equal(t, []string{"delete from user /*application='api'*/", "delete from user /*application='api'*/"}, fake.Queries())
+}
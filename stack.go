@@ -4,21 +4,10 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
-	"sync"
-)
 
-var (
-	stackCache sync.Map
+	"github.com/rfyiamcool/go-tracer/internal/stackutil"
 )
 
-type stackEntry struct {
-	frame runtime.Frame
-
-	function string
-	file     string
-	line     int
-}
-
 func GetFuncSkip(skip int) string {
 	return GetFunc(skip)
 }
@@ -39,42 +28,8 @@ func GetCallerCache() string {
 }
 
 func getCallerCache(skip int) (file string, line int, funcName string) {
-	// get stack pc offset
-	rpc := [1]uintptr{}
-	n := runtime.Callers(skip+2, rpc[:])
-	if n < 1 {
-		return
-	}
-
-	var (
-		frame runtime.Frame
-		pc    uintptr
-	)
-
-	pc = rpc[0]
-	item, ok := stackCache.Load(pc)
-	if ok {
-		si := item.(stackEntry)
-		return si.file, si.line, si.function
-	}
-
-	// get stack frame
-	frame, _ = runtime.CallersFrames([]uintptr{pc}).Next()
-
-	// get func name
-	funcPC := runtime.FuncForPC(pc)
-	if funcPC != nil {
-		funcName = trimFuncname(funcPC.Name())
-	}
-
-	si := stackEntry{
-		frame:    frame,
-		function: funcName,
-		file:     trimFilename(frame.File),
-		line:     frame.Line,
-	}
-	stackCache.Store(pc, si)
-	return si.file, si.line, si.function
+	si := stackutil.Caller(skip + 1)
+	return si.File, si.Line, si.Function
 }
 
 func GetCaller() string {
@@ -104,7 +59,7 @@ func getCaller(skip int) (string, int, uintptr) {
 }
 
 func trimFilename(file string) string {
-	return trimString(file, '/', 2)
+	return stackutil.TrimFilename(file)
 }
 
 // trimClassFuncname git.github.com/ocean/internal/controller.(*obj).run2 => controller.(*obj).run2
@@ -116,25 +71,5 @@ func trimClassFuncname(name string) string {
 
 // trimFuncname git.github.com/ocean/internal/controller.(*obj).run2 => (*obj).run2
 func trimFuncname(name string) string {
-	i := strings.LastIndex(name, "/")
-	name = name[i+1:]
-
-	i = strings.Index(name, ".")
-	return name[i+1:]
-}
-
-// trimString only retain shrot 2 level.
-func trimString(str string, seq byte, level int) string {
-	// get package name
-
-	n := 0
-	for i := len(str) - 1; i > 0; i-- {
-		if str[i] == seq {
-			n++
-			if n >= 2 {
-				return str[i+1:]
-			}
-		}
-	}
-	return str
+	return stackutil.TrimFuncname(name)
 }
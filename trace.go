@@ -0,0 +1,41 @@
+package tracer
+
+import (
+	"context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/rfyiamcool/go-tracer/internal/stackutil"
+)
+
+// Trace start the child span of ctx named by the caller function, with code.function, code.filepath
+// and code.lineno tags, end records the error of errp and finishes the span, errp can be nil.
+//
+//	func (s *Service) GetUser(ctx context.Context, id int) (user *User, err error) {
+//		ctx, end := tracer.Trace(ctx, opentracing.Tag{Key: "user.id", Value: id})
+//		defer end(&err)
+//		...
+//	}
+func Trace(ctx context.Context, tags ...opentracing.Tag) (context.Context, func(errp *error)) {
+	si := stackutil.Caller(1)
+
+	opts := make([]opentracing.StartSpanOption, 0, len(tags)+3)
+	opts = append(opts,
+		opentracing.Tag{Key: "code.function", Value: si.Function},
+		opentracing.Tag{Key: "code.filepath", Value: si.Frame.File},
+		opentracing.Tag{Key: "code.lineno", Value: si.Line},
+	)
+	for _, tag := range tags {
+		opts = append(opts, tag)
+	}
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, si.Function, opts...)
+	return ctx, func(errp *error) {
+		if errp != nil && *errp != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(log.Error(*errp))
+		}
+		span.Finish()
+	}
+}
@@ -0,0 +1,46 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+)
+
+func traceUser(ctx context.Context, id int) (err error) {
+	ctx, end := Trace(ctx, opentracing.Tag{Key: "user.id", Value: id})
+	defer end(&err)
+
+	if id == 0 {
+		return errors.New("not found")
+	}
+	return nil
+}
+
+func TestTrace(t *testing.T) {
+	mtracer := mocktracer.New()
+	SeteTracer(mtracer)
+
+	parent, ctx := StartSpanFromContext(context.Background(), "parent")
+	assert.Nil(t, traceUser(ctx, 1))
+	assert.NotNil(t, traceUser(ctx, 0))
+	parent.Finish()
+
+	spans := mtracer.FinishedSpans()
+	assert.Len(t, spans, 3)
+
+	for _, span := range spans[:2] {
+		assert.Equal(t, "traceUser", span.OperationName)
+		assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, span.ParentID)
+		assert.Equal(t, "traceUser", span.Tag("code.function"))
+		assert.Contains(t, span.Tag("code.filepath"), "trace_test.go")
+		assert.Equal(t, 14, span.Tag("code.lineno"))
+	}
+	assert.Equal(t, 1, spans[0].Tag("user.id"))
+	assert.Nil(t, spans[0].Tag("error"))
+	assert.Equal(t, true, spans[1].Tag("error"))
+	assert.Equal(t, "not found", spans[1].Logs()[0].Fields[0].ValueString)
+}
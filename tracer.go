@@ -334,6 +334,9 @@ func SpanFromContext(ctx context.Context) opentracing.Span {
 
 // GetTraceID get trace id from span
 func GetTraceID(span opentracing.Span) string {
+	if span == nil {
+		return ""
+	}
 	sc, ok := span.Context().(jaeger.SpanContext)
 	if ok {
 		return sc.TraceID().String()
@@ -404,6 +407,9 @@ func GetTraceSpanIDsFromCtx(ctx context.Context) (string, string) {
 
 // GetSpanID get span id
 func GetSpanID(span opentracing.Span) string {
+	if span == nil {
+		return ""
+	}
 	sc, ok := span.Context().(jaeger.SpanContext)
 	if ok {
 		return sc.SpanID().String()
@@ -414,6 +420,9 @@ func GetSpanID(span opentracing.Span) string {
 
 // GetTraceSpanIDs
 func GetTraceSpanIDs(span opentracing.Span) (string, string) {
+	if span == nil {
+		return "", ""
+	}
 	sc, ok := span.Context().(jaeger.SpanContext)
 	if ok {
 		return sc.TraceID().String(), sc.SpanID().String()
@@ -424,6 +433,9 @@ func GetTraceSpanIDs(span opentracing.Span) (string, string) {
 
 // GetParentID get parent span id
 func GetParentID(span opentracing.Span) string {
+	if span == nil {
+		return ""
+	}
 	sc, ok := span.Context().(jaeger.SpanContext)
 	if ok {
 		return sc.ParentID().String()
@@ -439,6 +451,9 @@ func GetFullTraceID(span opentracing.Span) string {
 
 // GetXTraceID get x-trace-id by span
 func GetXTraceID(span opentracing.Span) string {
+	if span == nil {
+		return ""
+	}
 	sc, ok := span.Context().(jaeger.SpanContext)
 	if ok {
 		// hex16(traceid):hex16(spanid):hex16(parentid):hex16(flag)
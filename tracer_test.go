@@ -0,0 +1,34 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestGetTraceSpanIDs(t *testing.T) {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+	SeteTracer(jtracer)
+
+	span, ctx := StartSpanFromContext(context.Background(), "parent")
+	defer span.Finish()
+	sc := span.Context().(jaeger.SpanContext)
+
+	traceID, spanID := GetTraceSpanIDsFromCtx(ctx)
+	assert.Equal(t, sc.TraceID().String(), traceID)
+	assert.Equal(t, sc.SpanID().String(), spanID)
+	assert.Equal(t, traceID, GetTraceIDFromCtx(ctx))
+	assert.Equal(t, spanID, GetSpanIDFromCtx(ctx))
+
+	// the context without span.
+	traceID, spanID = GetTraceSpanIDsFromCtx(context.Background())
+	assert.Empty(t, traceID)
+	assert.Empty(t, spanID)
+	assert.Empty(t, GetTraceIDFromCtx(context.Background()))
+	assert.Empty(t, GetSpanIDFromCtx(context.Background()))
+	assert.Empty(t, GetParentID(nil))
+	assert.Empty(t, GetXTraceID(nil))
+}
@@ -0,0 +1,156 @@
+package zaptrace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCore(t *testing.T) {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+
+	span := jtracer.StartSpan("get-user")
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+	sc := span.Context().(jaeger.SpanContext)
+
+	obs, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(NewCore(obs, WithSpanLog(zap.WarnLevel)))
+
+	logger.Info("get user", Context(ctx), zap.Int("id", 1))
+	logger.With(Context(ctx)).Warn("slow query", zap.Int("cost", 300))
+	logger.Warn("no context")
+	logger.Info("fields", Fields(ctx)...)
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 4)
+	for _, i := range []int{0, 1, 3} {
+		fields := entries[i].ContextMap()
+		assert.Equal(t, sc.TraceID().String(), fields["trace_id"])
+		assert.Equal(t, sc.SpanID().String(), fields["span_id"])
+		assert.Nil(t, fields["context"])
+	}
+	assert.Equal(t, int64(1), entries[0].ContextMap()["id"])
+	assert.Empty(t, entries[2].ContextMap())
+
+	// only the warn log with context is mirrored.
+	records := span.(*jaeger.Span).Logs()
+	assert.Len(t, records, 1)
+
+	fields := make(map[string]interface{})
+	for _, f := range records[0].Fields {
+		fields[f.Key()] = f.Value()
+	}
+	assert.Equal(t, "log", fields["event"])
+	assert.Equal(t, zapcore.WarnLevel.String(), fields["log.severity"])
+	assert.Equal(t, "slow query", fields["log.message"])
+	assert.Equal(t, int64(300), fields["cost"])
+	assert.Nil(t, fields["trace_id"])
+}
+
+func TestCoreSampler(t *testing.T) {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+
+	span := jtracer.StartSpan("get-user")
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	obs, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(NewCore(zapcore.NewSamplerWithOptions(obs, time.Second, 1, 0), WithSpanLog(zap.InfoLevel)))
+	for i := 0; i < 3; i++ {
+		logger.Info("get user", Context(ctx))
+	}
+
+	// the dropped entries of sampler are neither written nor mirrored.
+	assert.Equal(t, 1, logs.Len())
+	assert.NotNil(t, logs.All()[0].ContextMap()["trace_id"])
+	assert.Len(t, span.(*jaeger.Span).Logs(), 1)
+}
+
+func TestCoreTee(t *testing.T) {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+
+	span := jtracer.StartSpan("get-user")
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	infoObs, infoLogs := observer.New(zap.InfoLevel)
+	warnObs, warnLogs := observer.New(zap.WarnLevel)
+	logger := zap.New(NewCore(zapcore.NewTee(infoObs, warnObs)))
+
+	logger.Info("get user", Context(ctx))
+	logger.Warn("slow query", Context(ctx))
+
+	assert.Equal(t, 2, infoLogs.Len())
+	assert.Equal(t, 1, warnLogs.Len())
+	entry := warnLogs.All()[0]
+	assert.Equal(t, "slow query", entry.Message)
+	assert.NotNil(t, entry.ContextMap()["trace_id"])
+}
+
+func TestCoreContextOverride(t *testing.T) {
+	jtracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+
+	parent := jtracer.StartSpan("parent")
+	defer parent.Finish()
+	child := jtracer.StartSpan("child", opentracing.ChildOf(parent.Context()))
+	defer child.Finish()
+	parentCtx := opentracing.ContextWithSpan(context.Background(), parent)
+	childCtx := opentracing.ContextWithSpan(context.Background(), child)
+
+	obs, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(NewCore(obs)).With(Context(parentCtx))
+	logger.Info("get user", Context(childCtx))
+	logger.Info("list users")
+
+	// the Context of call replaces the one of With, trace_id and span_id are added once.
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, []string{"trace_id", "span_id"}, fieldKeys(entries[0]))
+	assert.Equal(t, child.Context().(jaeger.SpanContext).SpanID().String(), entries[0].ContextMap()["span_id"])
+	assert.Equal(t, []string{"trace_id", "span_id"}, fieldKeys(entries[1]))
+	assert.Equal(t, parent.Context().(jaeger.SpanContext).SpanID().String(), entries[1].ContextMap()["span_id"])
+}
+
+func fieldKeys(entry observer.LoggedEntry) []string {
+	var keys []string
+	for _, f := range entry.Context {
+		keys = append(keys, f.Key)
+	}
+	return keys
+}
+
+type errorWriter struct{}
+
+func (errorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func (errorWriter) Sync() error {
+	return nil
+}
+
+func TestCoreErrorOutput(t *testing.T) {
+	inner := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), errorWriter{}, zap.InfoLevel)
+	output := &bytes.Buffer{}
+	logger := zap.New(NewCore(inner), zap.ErrorOutput(zapcore.AddSync(output)))
+
+	// the write errors of inner core are reported to the ErrorOutput of logger once.
+	logger.Info("get user")
+	assert.Equal(t, 1, strings.Count(output.String(), "write error: disk full"))
+}
+
+func TestFieldsWithoutSpan(t *testing.T) {
+	assert.Nil(t, Fields(context.Background()))
+}